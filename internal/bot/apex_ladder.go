@@ -0,0 +1,271 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dg "github.com/bwmarrin/discordgo"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+const (
+	// apexLadderRefreshInterval is how often the Master, Grandmaster and Challenger ladders are fetched again.
+	apexLadderRefreshInterval = 30 * time.Minute
+	// apexLadderRefreshTimeout bounds the requests of a single ladder refresh.
+	apexLadderRefreshTimeout = 2 * time.Minute
+)
+
+// apexLadderQueues are the queues whose apex ladders are cached.
+var apexLadderQueues = []string{riotapi.QueueTypeRankedSolo, riotapi.QueueTypeRankedFlex}
+
+// apexLadder caches the apex ladders of the configured platform, one per ranked queue.
+type apexLadder struct {
+	platform string
+
+	mu      sync.RWMutex
+	ladders map[string]*ladder
+}
+
+// ladder is the apex ladder of a queue.
+type ladder struct {
+	// positions maps the puuid of every apex player to their position, 1 being the best player
+	positions map[string]int
+	// grandmasterCutoff and challengerCutoff are the LP of the lowest Grandmaster and Challenger players,
+	// 0 when the tier is empty
+	grandmasterCutoff int
+	challengerCutoff  int
+}
+
+func newApexLadder(platform string) *apexLadder {
+	return &apexLadder{
+		platform: platform,
+		ladders:  make(map[string]*ladder),
+	}
+}
+
+// TrackApexLadders refreshes the apex ladders of the configured platform until the bot context is cancelled,
+// periodically and whenever this instance takes a lease (see requestApexLadderRefresh).
+func (b *Bot) TrackApexLadders() {
+	ticker := time.NewTicker(apexLadderRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			log.Println("Stopping apex ladder tracking")
+			return
+		case <-b.apexLadderRefresh:
+			b.refreshApexLadders()
+		case <-ticker.C:
+			b.refreshApexLadders()
+		}
+	}
+}
+
+// requestApexLadderRefresh makes TrackApexLadders refresh the ladders right away, without waiting for the ticker.
+func (b *Bot) requestApexLadderRefresh() {
+	select {
+	case b.apexLadderRefresh <- struct{}{}:
+	default:
+		// a refresh is already pending
+	}
+}
+
+// refreshApexLadders fetches the apex ladder of every cached queue, on the leader for its announcements and on the
+// instances answering /list. A ladder that can't be fetched keeps its previous value.
+func (b *Bot) refreshApexLadders() {
+	if (!b.isLeader() && !b.answersInteractions()) || b.riotClient.KeyInvalid() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, apexLadderRefreshTimeout)
+	defer cancel()
+
+	for _, queueType := range apexLadderQueues {
+		l, err := b.fetchLadder(ctx, queueType)
+		if err != nil {
+			log.Printf("Error fetching %s apex ladder: %v", riotapi.QueueTypeName(queueType), err)
+			continue
+		}
+
+		b.apexLadder.mu.Lock()
+		b.apexLadder.ladders[queueType] = l
+		b.apexLadder.mu.Unlock()
+	}
+}
+
+// fetchLadder fetches the Challenger, Grandmaster and Master leagues of a queue and ranks their players by LP.
+func (b *Bot) fetchLadder(ctx context.Context, queueType string) (*ladder, error) {
+	l := &ladder{positions: make(map[string]int)}
+
+	position := 0
+	for _, tier := range riotapi.ApexTiers {
+		league, err := b.riotClient.GetApexLeague(ctx, b.apexLadder.platform, tier, queueType)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s league: %w", strings.ToLower(tier), err)
+		}
+
+		entries := league.Entries
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].LeaguePoints > entries[j].LeaguePoints
+		})
+
+		for _, entry := range entries {
+			position++
+			l.positions[entry.PUUID] = position
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		cutoff := entries[len(entries)-1].LeaguePoints
+		switch tier {
+		case "CHALLENGER":
+			l.challengerCutoff = cutoff
+		case "GRANDMASTER":
+			l.grandmasterCutoff = cutoff
+		}
+	}
+
+	return l, nil
+}
+
+// position returns the ladder position of a player in a queue, and false when the player isn't an apex player
+// of the configured platform.
+func (a *apexLadder) position(platform, queueType, puuid string) (int, bool) {
+	if !strings.EqualFold(platform, a.platform) {
+		return 0, false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	l, ok := a.ladders[queueType]
+	if !ok {
+		return 0, false
+	}
+
+	position, ok := l.positions[puuid]
+	return position, ok
+}
+
+// cutoffs returns the Grandmaster and Challenger LP cutoffs of a queue, and false when they aren't known.
+func (a *apexLadder) cutoffs(platform, queueType string) (grandmaster, challenger int, ok bool) {
+	if !strings.EqualFold(platform, a.platform) {
+		return 0, 0, false
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	l, ok := a.ladders[queueType]
+	if !ok {
+		return 0, 0, false
+	}
+
+	return l.grandmasterCutoff, l.challengerCutoff, true
+}
+
+// formatLadderPosition returns the ladder position of a player (e.g. "#142 EUW"), or "" when they aren't ranked on it.
+func (b *Bot) formatLadderPosition(platform, queueType, puuid string) string {
+	position, ok := b.apexLadder.position(platform, queueType, puuid)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("#%d %s", position, riotapi.PlatformDisplayName(platform))
+}
+
+// formatRankWithLadder returns the rank of a player followed by their ladder position for apex players,
+// e.g. "MASTER (312lp) #142 EUW".
+func (b *Bot) formatRankWithLadder(summoner riotapi.Summoner, rankInfo *riotapi.LeagueEntry) string {
+	rank := u.FormatRank(rankInfo.Tier, rankInfo.Rank, rankInfo.LeaguePoints)
+	if !u.IsApexTier(rankInfo.Tier) {
+		return rank
+	}
+
+	if position := b.formatLadderPosition(summoner.Region, rankInfo.QueueType, summoner.SummonerPUUID); position != "" {
+		rank = fmt.Sprintf("%s %s", rank, position)
+	}
+
+	return rank
+}
+
+// checkApexCutoffs announces when a summoner crossed the LP cutoff of the tier above theirs
+// (Master to Grandmaster, or Grandmaster to Challenger). Riot promotes them at the next daily ladder update.
+func (b *Bot) checkApexCutoffs(summoner s.SummonerWithGuilds, previousRank *s.PreviousRank, current *riotapi.LeagueEntry) {
+	if !u.IsApexTier(previousRank.PrevTier) || !u.IsApexTier(current.Tier) {
+		return
+	}
+
+	grandmasterCutoff, challengerCutoff, ok := b.apexLadder.cutoffs(summoner.Summoner.Region, current.QueueType)
+	if !ok {
+		return
+	}
+
+	crossed := func(cutoff int) bool {
+		return cutoff > 0 && previousRank.PrevLP < cutoff && current.LeaguePoints >= cutoff
+	}
+
+	var nextTier string
+	var cutoff int
+	switch strings.ToUpper(current.Tier) {
+	case "GRANDMASTER":
+		if crossed(challengerCutoff) {
+			nextTier, cutoff = "CHALLENGER", challengerCutoff
+		}
+	case "MASTER":
+		if crossed(challengerCutoff) {
+			nextTier, cutoff = "CHALLENGER", challengerCutoff
+		} else if crossed(grandmasterCutoff) {
+			nextTier, cutoff = "GRANDMASTER", grandmasterCutoff
+		}
+	}
+
+	if nextTier == "" {
+		return
+	}
+
+	embed := b.prepareApexCutoffEmbed(summoner.Summoner, current, nextTier, cutoff)
+
+	for _, guildID := range summoner.GuildIDs {
+		if err := b.announceNewMatch(guildID, embed); err != nil {
+			log.Printf("Error announcing %s cutoff for %s in guild %s: %v", strings.ToLower(nextTier), summoner.Summoner.Name, guildID, err)
+		}
+	}
+
+	log.Printf("%s crossed the %s cutoff", summoner.Summoner.Name, strings.ToLower(nextTier))
+}
+
+// prepareApexCutoffEmbed returns an embed announcing that a summoner crossed the LP cutoff of nextTier.
+func (b *Bot) prepareApexCutoffEmbed(summoner riotapi.Summoner, current *riotapi.LeagueEntry, nextTier string, cutoff int) *dg.MessageEmbed {
+	nextTierName := u.CapitalizeFirst(strings.ToLower(nextTier))
+
+	description := fmt.Sprintf("**%dLP**, above the %s cutoff of **%dLP**. Promotion comes with the next daily ladder update.", current.LeaguePoints, nextTierName, cutoff)
+	if position := b.formatLadderPosition(summoner.Region, current.QueueType, summoner.SummonerPUUID); position != "" {
+		description = fmt.Sprintf("%s\nLadder: **%s**", description, position)
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       fmt.Sprintf("%s crossed the %s cutoff! 🚀", summoner.Name, nextTierName),
+		Description: description,
+		Color:       u.GetRankColor(nextTier),
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueTypeName(current.QueueType),
+		},
+		Thumbnail: thumbnail(b.ddragon.ProfileIconURL(summoner.ProfileIconID)),
+		Footer: &dg.MessageEmbedFooter{
+			Text: u.FormatTime(time.Now().UnixMilli()),
+		},
+	}
+
+	return embed
+}
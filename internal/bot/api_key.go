@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/tristan-derez/league-tracker/internal/config"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+)
+
+const (
+	// apiKeyPollInterval is how often RIOT_API_KEY_FILE and the key stored with /apikey are read for a new key.
+	apiKeyPollInterval = time.Minute
+	// apiKeyCheckTimeout bounds the request made to check a key set with /apikey.
+	apiKeyCheckTimeout = 30 * time.Second
+)
+
+// handleInvalidAPIKey is called by the Riot client when Riot rejects the API key. Every Riot request fails
+// until a new key is set, so the loops skip their polling and the operator is told how to fix it.
+func (b *Bot) handleInvalidAPIKey(err error) {
+	howToFix := "with /apikey"
+	if b.config.RiotAPIKeyFile != "" {
+		howToFix = fmt.Sprintf("in %s or with /apikey", b.config.RiotAPIKeyFile)
+	}
+
+	go b.alertOperator(fmt.Sprintf("⚠️ Riot rejected the API key (%v). Tracking is suspended until a new key is set %s.", err, howToFix))
+}
+
+// alertOperator sends a message to the bot owner in DM and to the admin channel, the ones configured.
+func (b *Bot) alertOperator(message string) {
+	if b.config.BotOwnerID == "" && b.config.AdminChannelID == "" {
+		log.Printf("No BOT_OWNER_ID or ADMIN_CHANNEL_ID set to send the alert to: %s", message)
+		return
+	}
+
+	if b.config.BotOwnerID != "" {
+		channel, err := b.session.UserChannelCreate(b.config.BotOwnerID)
+		if err != nil {
+			log.Printf("Error opening DM channel with the bot owner: %v", err)
+		} else if _, err := b.session.ChannelMessageSend(channel.ID, message); err != nil {
+			log.Printf("Error sending alert to the bot owner: %v", err)
+		}
+	}
+
+	if b.config.AdminChannelID != "" {
+		if _, err := b.session.ChannelMessageSend(b.config.AdminChannelID, message); err != nil {
+			log.Printf("Error sending alert to the admin channel: %v", err)
+		}
+	}
+}
+
+// WatchAPIKey switches the Riot client to a new key when one is written to RIOT_API_KEY_FILE or set with /apikey
+// on any instance, until the bot context is cancelled.
+func (b *Bot) WatchAPIKey() {
+	lastFileKey := b.config.RiotAPIKey
+
+	// a key stored before this instance started is only used once Riot rejects the one it started with
+	_, lastStoredAt, err := b.storage.GetRiotAPIKey(b.ctx)
+	if err != nil {
+		log.Print(err)
+	}
+
+	ticker := time.NewTicker(apiKeyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			log.Println("Stopping Riot API key watch")
+			return
+		case <-ticker.C:
+			if b.config.RiotAPIKeyFile != "" {
+				lastFileKey = b.loadAPIKeyFile(lastFileKey)
+			}
+			lastStoredAt = b.loadStoredAPIKey(lastStoredAt)
+		}
+	}
+}
+
+// loadAPIKeyFile switches to the key of RIOT_API_KEY_FILE if it isn't lastKey, and returns the key of the file.
+func (b *Bot) loadAPIKeyFile(lastKey string) string {
+	apiKey, err := config.ReadAPIKeyFile(b.config.RiotAPIKeyFile)
+	if err != nil {
+		log.Print(err)
+		return lastKey
+	}
+
+	if apiKey == "" || apiKey == lastKey {
+		return lastKey
+	}
+
+	if b.setAPIKey(apiKey, b.config.RiotAPIKeyFile) {
+		b.alertOperator(fmt.Sprintf("✅ New Riot API key loaded from %s, tracking resumed.", b.config.RiotAPIKeyFile))
+	}
+
+	return apiKey
+}
+
+// loadStoredAPIKey switches to the key stored with /apikey if it was set after lastStoredAt or if Riot rejected
+// the current key, and returns when the stored key was set.
+func (b *Bot) loadStoredAPIKey(lastStoredAt time.Time) time.Time {
+	apiKey, storedAt, err := b.storage.GetRiotAPIKey(b.ctx)
+	if err != nil {
+		log.Print(err)
+		return lastStoredAt
+	}
+
+	if apiKey == "" || apiKey == b.riotClient.APIKey() {
+		return storedAt
+	}
+
+	if storedAt.After(lastStoredAt) || b.riotClient.KeyInvalid() {
+		// the instance that answered /apikey already told the owner whether Riot accepts the key
+		b.setAPIKey(apiKey, "/apikey")
+	}
+
+	return storedAt
+}
+
+// setAPIKey switches the Riot client to a new key and reports whether the previous one was invalid.
+func (b *Bot) setAPIKey(apiKey, source string) bool {
+	wasInvalid := b.riotClient.KeyInvalid()
+	b.riotClient.SetAPIKey(apiKey)
+
+	log.Printf("Riot API key replaced from %s", source)
+
+	return wasInvalid
+}
+
+// handleAPIKey processes the /apikey command for the Discord bot.
+// It lets the bot owner replace the Riot API key without restarting, and checks the new key right away.
+func (b *Bot) handleAPIKey(s *dg.Session, i *dg.InteractionCreate) {
+	if b.config.BotOwnerID == "" || interactionUserID(i) != b.config.BotOwnerID {
+		respondWithError(s, i, "Only the owner of the bot can change the Riot API key.")
+		return
+	}
+
+	apiKey := strings.TrimSpace(mapOptionsByName(i.ApplicationCommandData().Options)["key"].StringValue())
+
+	// the key is part of the command, the answer is only shown to the owner
+	err := s.InteractionRespond(i.Interaction, &dg.InteractionResponse{
+		Type: dg.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &dg.InteractionResponseData{
+			Flags: dg.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("Error acknowledging interaction: %v", err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(b.ctx, apiKeyCheckTimeout)
+		defer cancel()
+
+		// the instance running the trackers may not be this one, every instance loads the stored key
+		var content string
+		if err := b.storage.SaveRiotAPIKey(ctx, apiKey); err != nil {
+			log.Print(err)
+			content = "❌ The Riot API key couldn't be saved, please try again later."
+		} else {
+			b.setAPIKey(apiKey, "/apikey")
+
+			if err := b.riotClient.CheckAPIKey(ctx, b.config.RiotAPIRegion); err != nil {
+				if riotapi.IsInvalidKeyError(err) {
+					content = "❌ Riot rejected this key too, tracking is still suspended."
+				} else {
+					content = fmt.Sprintf("⚠️ Riot API key updated, but it couldn't be checked: %v", err)
+				}
+			} else {
+				content = "✅ Riot API key updated, tracking resumes within a minute."
+			}
+		}
+
+		if _, err := s.InteractionResponseEdit(i.Interaction, &dg.WebhookEdit{Content: &content}); err != nil {
+			log.Printf("Error editing interaction response: %v", err)
+		}
+	}()
+}
+
+// interactionUserID returns the ID of the user who triggered an interaction, in a guild or in DM.
+func interactionUserID(i *dg.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+
+	if i.User != nil {
+		return i.User.ID
+	}
+
+	return ""
+}
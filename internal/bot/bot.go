@@ -8,57 +8,132 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/tristan-derez/league-tracker/internal/config"
+	"github.com/tristan-derez/league-tracker/internal/ddragon"
 	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
 	"github.com/tristan-derez/league-tracker/internal/storage"
 )
 
+const (
+	// ddragonRefreshInterval is how often a new Data Dragon version is looked for.
+	ddragonRefreshInterval = time.Hour
+	// ddragonRefreshTimeout bounds the initial Data Dragon download, a first run fetches every file.
+	ddragonRefreshTimeout = time.Minute
+)
+
 // Bot struct represents the Discord bot and holds references to its dependencies
 type Bot struct {
-	session    *discordgo.Session
-	storage    *storage.Storage
-	riotClient *riotapi.Client
-	wg         sync.WaitGroup
-	mu         sync.Mutex
-	ctx        context.Context
-	cancel     context.CancelFunc
+	session      *discordgo.Session
+	storage      *storage.Storage
+	riotClient   *riotapi.Client
+	ddragon      *ddragon.Client
+	apexLadder   *apexLadder
+	liveGames    *liveGameState
+	matchGroups  *matchGroups
+	config       *config.Config
+	wg           sync.WaitGroup
+	trackingOnce sync.Once
+	mu           sync.Mutex
+	ctx          context.Context
+	cancel       context.CancelFunc
+
+	// apexLadderRefresh asks TrackApexLadders for a refresh, see requestApexLadderRefresh
+	apexLadderRefresh chan struct{}
+
+	// matchHistoryDown holds the platforms whose match history Riot reports unavailable, see TrackRiotStatus
+	matchHistoryDown downRegions
+
+	// instanceID identifies this instance in the leases table, see RunLeaderElection
+	instanceID   string
+	trackerLease lease
+	shardLease   lease
 }
 
 // New creates and initializes a new Bot instance
 func New(cfg *config.Config) (*Bot, error) {
+	defaultRegion, err := riotapi.NormalizePlatform(cfg.RiotAPIRegion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RIOT_REGION: %w", err)
+	}
+	cfg.RiotAPIRegion = defaultRegion
+
 	session, err := discordgo.New("Bot " + cfg.DiscordToken)
 	if err != nil {
 		return nil, err
 	}
+	// Discord refuses a single connection once the bot is in 2,500 guilds, each process then connects as one shard
+	session.ShardID = cfg.ShardID
+	session.ShardCount = cfg.ShardCount
 
 	storage, err := storage.New(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	riotClient := riotapi.NewClient(cfg.RiotAPIKey, cfg.RiotAPIRegion)
-
 	ctx, cancel := context.WithCancel(context.Background())
 
+	ddragonClient := ddragon.New(cfg.DDragonCacheDir)
+	refreshCtx, refreshCancel := context.WithTimeout(ctx, ddragonRefreshTimeout)
+	if err := ddragonClient.Refresh(refreshCtx); err != nil {
+		log.Printf("Warning: no Data Dragon data available, champion names and images won't be resolved: %v", err)
+	}
+	refreshCancel()
+
 	bot := &Bot{
-		session:    session,
-		storage:    storage,
-		riotClient: riotClient,
-		ctx:        ctx,
-		cancel:     cancel,
+		session:     session,
+		storage:     storage,
+		ddragon:     ddragonClient,
+		apexLadder:  newApexLadder(cfg.RiotAPIRegion),
+		liveGames:   newLiveGameState(),
+		matchGroups: newMatchGroups(),
+		config:      cfg,
+		ctx:         ctx,
+		cancel:      cancel,
+		instanceID:  newInstanceID(),
 	}
+	bot.apexLadderRefresh = make(chan struct{}, 1)
+	bot.trackerLease.name = trackerLeaseName
+	bot.shardLease.name = shardLeaseName(cfg.ShardID)
+
+	riotOpts := append(riotClientOptions(cfg), riotapi.WithInvalidKeyHandler(bot.handleInvalidAPIKey))
+	bot.riotClient = riotapi.NewClient(cfg.RiotAPIKey, cfg.RiotAPIRegion, riotOpts...)
 
 	return bot, nil
 }
 
+// riotClientOptions returns the options of the Riot client for the RIOT_HTTP_MODE and RIOT_BASE_URL settings.
+func riotClientOptions(cfg *config.Config) []riotapi.ClientOption {
+	var opts []riotapi.ClientOption
+
+	if cfg.RiotBaseURL != "" {
+		opts = append(opts, riotapi.WithBaseURL(cfg.RiotBaseURL))
+	}
+
+	switch cfg.RiotHTTPMode {
+	case "record":
+		log.Printf("Recording Riot API responses to %s", cfg.RiotFixturesDir)
+		opts = append(opts, riotapi.WithTransport(&riotapi.RecordingTransport{Dir: cfg.RiotFixturesDir}))
+	case "replay":
+		log.Printf("Replaying Riot API responses from %s, no request will reach Riot", cfg.RiotFixturesDir)
+		opts = append(opts, riotapi.WithTransport(&riotapi.ReplayTransport{Dir: cfg.RiotFixturesDir}))
+	}
+
+	return opts
+}
+
 // Run starts the bot and sets up event handlers
 func (b *Bot) Run() error {
 	b.session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		log.Println("Bot is now ready")
-		if err := b.registerCommandsOnce(); err != nil {
-			log.Printf("Failed to register commands: %v", err)
+		log.Printf("Bot is now ready (shard %d/%d, %d guilds)", s.ShardID, s.ShardCount, len(r.Guilds))
+
+		// commands are global, a single shard registers them
+		if s.ShardID == 0 {
+			if err := b.registerCommandsOnce(); err != nil {
+				log.Printf("Failed to register commands: %v", err)
+			}
 		}
 
 		b.mu.Lock()
@@ -70,12 +145,61 @@ func (b *Bot) Run() error {
 		b.mu.Unlock()
 		log.Println("Initial guild setup complete")
 
-		go b.TrackMatches()
+		// Ready is sent again after a reconnection, the tracker must only be started once
+		b.trackingOnce.Do(func() {
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.TrackMatches()
+			}()
+
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.TrackLiveGames()
+			}()
+
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.ddragon.RefreshPeriodically(b.ctx, ddragonRefreshInterval)
+			}()
+
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.TrackApexLadders()
+			}()
+
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.TrackRiotStatus()
+			}()
+
+			b.wg.Add(1)
+			go func() {
+				defer b.wg.Done()
+				b.WatchAPIKey()
+			}()
+		})
 	})
 
 	b.session.AddHandler(b.handleGuildCreate)
 	b.session.AddHandler(b.handleInteraction)
 
+	// every instance connected as the same shard receives its Discord events, only the holder of its lease acts on them
+	b.renewLeases()
+	if !b.isLeader() {
+		log.Printf("Instance %s is standing by, another instance runs the trackers", b.instanceID)
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.RunLeaderElection()
+	}()
+
 	err := b.session.Open()
 	if err != nil {
 		return err
@@ -112,6 +236,13 @@ func (b *Bot) registerCommandsOnce() error {
 					Description: "The summoner name(s) to add to the followed list (comma-separated for multiple)",
 					Required:    true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "region",
+					Description: "The region the summoner(s) play on (defaults to the bot's region)",
+					Required:    false,
+					Choices:     regionChoices(),
+				},
 			},
 		},
 		{
@@ -138,6 +269,58 @@ func (b *Bot) registerCommandsOnce() error {
 			Name:        "list",
 			Description: "List all followed summoners",
 		},
+		{
+			Name:        "queues",
+			Description: "Show or change the queues (solo, flex, aram...) announced in this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "queues",
+					Description: "The queues to announce (comma-separated, e.g. solo, flex), or 'default'",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "summoner",
+					Description: "Only change the queues announced for this summoner",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "mastery",
+			Description: "Show the champions a League of Legends summoner has the most mastery points on",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "summoner",
+					Description: "The summoner name (Name#Tag)",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "region",
+					Description: "The region the summoner plays on (defaults to the bot's region)",
+					Required:    false,
+					Choices:     regionChoices(),
+				},
+			},
+		},
+	}
+
+	if b.config.BotOwnerID != "" {
+		commands = append(commands, &discordgo.ApplicationCommand{
+			Name:        "apikey",
+			Description: "Replace the Riot API key of the bot (bot owner only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "key",
+					Description: "The new Riot API key (RGAPI-...)",
+					Required:    true,
+				},
+			},
+		})
 	}
 
 	for _, v := range commands {
@@ -152,6 +335,19 @@ func (b *Bot) registerCommandsOnce() error {
 	return nil
 }
 
+// regionChoices returns the choices offered for the "region" command option, one per supported platform.
+func regionChoices() []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, platform := range riotapi.Platforms() {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  fmt.Sprintf("%s (%s)", riotapi.PlatformDisplayName(platform), platform),
+			Value: platform,
+		})
+	}
+
+	return choices
+}
+
 // handleGuildCreate is called when the bot joins a new Discord guild (server).
 // It adds the guild to the database and starts tracking matches for it.
 func (b *Bot) handleGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
@@ -1,9 +1,11 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,6 +18,11 @@ import (
 
 // handleInteraction is a method of the Bot struct that handles Discord interactions
 func (b *Bot) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// the interaction is sent to every instance connected as the shard of the guild, one of them answers it
+	if !b.answersInteractions() {
+		return
+	}
+
 	switch i.ApplicationCommandData().Name {
 	case "add":
 		b.handleAdd(s, i)
@@ -27,9 +34,18 @@ func (b *Bot) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCr
 		b.handleList(s, i)
 	case "unchannel":
 		b.handleUnchannel(s, i)
+	case "queues":
+		b.handleQueues(s, i)
+	case "mastery":
+		b.handleMastery(s, i)
+	case "apikey":
+		b.handleAPIKey(s, i)
 	}
 }
 
+// addSummonersTimeout bounds the Riot and database lookups made in the background when adding summoners.
+const addSummonersTimeout = 2 * time.Minute
+
 // handleAdd processes the "add" command for the Discord bot.
 // It adds one or more summoners to the bot's tracking system.
 func (b *Bot) handleAdd(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -40,7 +56,18 @@ func (b *Bot) handleAdd(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	summonerNames := strings.Split(options[0].StringValue(), ",")
+	optionMap := mapOptionsByName(options)
+	summonerNames := strings.Split(optionMap["summoners"].StringValue(), ",")
+
+	region := b.config.RiotAPIRegion
+	if option, ok := optionMap["region"]; ok {
+		normalized, err := riotapi.NormalizePlatform(option.StringValue())
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		region = normalized
+	}
 
 	if err := respondToInteractionWithSource(s, i, "Adding summoner(s)..."); err != nil {
 		log.Printf("Error responding to interaction: %v", err)
@@ -51,10 +78,13 @@ func (b *Bot) handleAdd(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	resultChan := make(chan string, 1)
 
 	go func() {
+		ctx, cancel := context.WithTimeout(b.ctx, addSummonersTimeout)
+		defer cancel()
+
 		var responses []string
 
 		for _, summonerName := range summonerNames {
-			response := b.processSingleSummoner(summonerName, i.GuildID, i.ChannelID)
+			response := b.processSingleSummoner(ctx, summonerName, region, i.GuildID, i.ChannelID)
 			responses = append(responses, response)
 		}
 
@@ -79,7 +109,7 @@ func (b *Bot) handleAdd(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 }
 
-func (b *Bot) processSingleSummoner(summonerName, guildID, channelID string) string {
+func (b *Bot) processSingleSummoner(ctx context.Context, summonerName, region, guildID, channelID string) string {
 	summonerName = strings.TrimSpace(summonerName)
 	parts := strings.SplitN(summonerName, "#", 2)
 
@@ -98,56 +128,72 @@ func (b *Bot) processSingleSummoner(summonerName, guildID, channelID string) str
 	}
 
 	if exists {
-		rankInfo, err := b.storage.GetLeagueEntry(summonerUUID)
+		rankInfo, err := b.storage.GetLeagueEntry(summonerUUID, riotapi.QueueTypeRankedSolo)
 		if err != nil {
 			log.Printf("Error fetching league entry for '%s': %v", summonerName, err)
 			return "❌ Error fetching summoner rank."
 		}
 
-		return b.formatSummonerResponse(summonerName, rankInfo, uuid.Nil, "")
+		return b.formatSummonerResponse(ctx, summonerName, rankInfo, uuid.Nil, "", "")
 	}
 
-	account, err := b.riotClient.GetAccountPUUIDBySummonerName(gameName, tagLine)
+	account, err := b.riotClient.GetAccountPUUIDBySummonerName(ctx, region, gameName, tagLine)
 	if err != nil {
 		return fmt.Sprintf("❌ Unable to find '%s': %v", summonerName, err)
 	}
 
 	fullNameOriginalCasing := fmt.Sprintf("%s#%s", account.SummonerName, account.SummonerTagLine)
 
-	summoner, err := b.riotClient.GetSummonerByPUUID(account.SummonerPUUID)
+	summoner, err := b.riotClient.GetSummonerByPUUID(ctx, region, account.SummonerPUUID)
 	if err != nil {
-		log.Printf("Error fetching details for '%s': %v", summonerName, err)
+		log.Printf("Error fetching details for '%s' on %s: %v", summonerName, region, err)
+		return fmt.Sprintf("❌ Unable to find '%s' on %s.", summonerName, riotapi.PlatformDisplayName(region))
 	}
 
-	rankInfo, err := b.riotClient.GetSummonerRank(account.SummonerPUUID)
+	leagueEntries, err := b.riotClient.GetLeagueEntries(ctx, region, account.SummonerPUUID)
 	if err != nil {
 		log.Printf("Error fetching rank for '%s': %v", summonerName, err)
+		return fmt.Sprintf("❌ Unable to fetch the rank of '%s'.", summonerName)
 	}
 
-	summonerUUID, err = b.storage.AddSummoner(guildID, channelID, fullNameOriginalCasing, *summoner, rankInfo)
+	rankInfo := riotapi.FindLeagueEntry(leagueEntries, riotapi.QueueTypeRankedSolo)
+	flexRankInfo := riotapi.FindLeagueEntry(leagueEntries, riotapi.QueueTypeRankedFlex)
+
+	summonerUUID, err = b.storage.AddSummoner(guildID, channelID, fullNameOriginalCasing, *summoner, []*riotapi.LeagueEntry{rankInfo, flexRankInfo})
 	if err != nil {
 		log.Printf("Error adding '%s' to database: %v", summonerName, err)
 		return fmt.Sprintf("❌ Error adding '%s' to database.", summonerName)
 	}
 
-	go b.addLastMatchData(summoner.RiotSummonerID, account.SummonerPUUID, *rankInfo)
+	trackedQueues, err := b.storage.GetGuildTrackedQueues(guildID)
+	if err != nil {
+		log.Printf("Error fetching tracked queues of guild %s: %v", guildID, err)
+		trackedQueues = riotapi.DefaultTrackedQueues
+	}
 
-	return b.formatSummonerResponse(fullNameOriginalCasing, rankInfo, summonerUUID, account.SummonerPUUID)
-}
+	go b.addLastMatchData(summonerUUID, region, account.SummonerPUUID, trackedQueues, []*riotapi.LeagueEntry{rankInfo, flexRankInfo})
 
-func (b *Bot) formatSummonerResponse(summonerName string, rankInfo *riotapi.LeagueEntry, summonerUUID uuid.UUID, summonerPUUID string) string {
-	if rankInfo.Tier == "UNRANKED" && rankInfo.Rank == "" {
-		placementStatus, err := b.riotClient.GetPlacementStatus(summonerPUUID)
-		if err != nil {
-			log.Printf("Error fetching placement status for '%s': %v", summonerName, err)
-			return fmt.Sprintf("❌ Error fetching placement status for %s", summonerName)
+	response := b.formatSummonerResponse(ctx, fullNameOriginalCasing, rankInfo, summonerUUID, region, account.SummonerPUUID)
+
+	if slices.Contains(trackedQueues, riotapi.QueueRankedFlex) {
+		if flexRankInfo.Tier == "UNRANKED" {
+			if _, err := b.initializePlacementGames(ctx, summonerUUID, region, account.SummonerPUUID, riotapi.QueueRankedFlex); err != nil {
+				log.Printf("Error initializing flex placement games for %s: %v", fullNameOriginalCasing, err)
+			}
+		} else {
+			response += fmt.Sprintf(" (Flex: %s %s %d LP)", flexRankInfo.Tier, flexRankInfo.Rank, flexRankInfo.LeaguePoints)
 		}
+	}
+
+	return response
+}
 
-		currentSeason := b.storage.GetCurrentSeason()
-		err = b.storage.InitializePlacementGames(summonerUUID, currentSeason, placementStatus)
+func (b *Bot) formatSummonerResponse(ctx context.Context, summonerName string, rankInfo *riotapi.LeagueEntry, summonerUUID uuid.UUID, region, summonerPUUID string) string {
+	if rankInfo.Tier == "UNRANKED" && rankInfo.Rank == "" {
+		placementStatus, err := b.initializePlacementGames(ctx, summonerUUID, region, summonerPUUID, riotapi.QueueRankedSolo)
 		if err != nil {
 			log.Printf("Error initializing placement games for summoner %s: %v", summonerName, err)
-			return fmt.Sprintf("❌ Error while initializing placement games for %s", summonerName)
+			return fmt.Sprintf("❌ Error fetching placement status for %s", summonerName)
 		}
 
 		if placementStatus.IsInPlacements {
@@ -160,20 +206,74 @@ func (b *Bot) formatSummonerResponse(summonerName string, rankInfo *riotapi.Leag
 		summonerName, rankInfo.Tier, rankInfo.Rank, rankInfo.LeaguePoints)
 }
 
-func (b *Bot) addLastMatchData(summonerID, puuid string, rankInfo riotapi.LeagueEntry) {
-	lastMatchData, err := b.riotClient.GetLastRankedSoloMatchData(puuid)
+// initializePlacementGames counts the placement games a summoner already played in a ranked queue this split,
+// and stores them so the tracker can keep counting.
+func (b *Bot) initializePlacementGames(ctx context.Context, summonerUUID uuid.UUID, region, summonerPUUID string, queueID int) (*riotapi.PlacementStatus, error) {
+	currentSeason := b.storage.GetCurrentSeason()
 
+	placementStatus, err := b.riotClient.GetPlacementStatus(ctx, region, summonerPUUID, queueID, currentSeason.Start)
 	if err != nil {
-		log.Printf("error retrieving ranked games for '%s': %v", summonerID, err)
-		return
+		return nil, fmt.Errorf("error fetching placement status: %w", err)
 	}
 
-	if lastMatchData != nil {
-		_, err := b.storage.AddMatchAndGetLPChange(summonerID, lastMatchData, rankInfo.LeaguePoints, rankInfo.Rank, rankInfo.Tier)
-		if err != nil {
-			log.Printf("Error adding match data for '%s': %v", summonerID, err)
+	err = b.storage.InitializePlacementGames(ctx, summonerUUID, currentSeason, riotapi.LeagueQueueType(queueID), placementStatus)
+	if err != nil {
+		return nil, fmt.Errorf("error storing placement games: %w", err)
+	}
+
+	return placementStatus, nil
+}
+
+// addLastMatchData stores the last match of a newly tracked summoner in each tracked queue,
+// so only the games played from now on are announced.
+func (b *Bot) addLastMatchData(summonerUUID uuid.UUID, region, puuid string, queueIDs []int, leagueEntries []*riotapi.LeagueEntry) {
+	ctx, cancel := context.WithTimeout(b.ctx, addSummonersTimeout)
+	defer cancel()
+
+	for _, queueID := range queueIDs {
+		if err := b.storeLastMatch(ctx, summonerUUID, region, puuid, queueID, leagueEntries); err != nil {
+			log.Printf("Error adding %s match data for '%s': %v", riotapi.QueueName(queueID), puuid, err)
+		}
+	}
+}
+
+// storeLastMatch stores the last match a summoner played in a queue without announcing it,
+// along with their current rank for ranked queues that have no stored rank yet.
+func (b *Bot) storeLastMatch(ctx context.Context, summonerUUID uuid.UUID, region, puuid string, queueID int, leagueEntries []*riotapi.LeagueEntry) error {
+	lastMatchData, err := b.riotClient.GetLastMatchData(ctx, region, puuid, queueID)
+	if err != nil {
+		return fmt.Errorf("error retrieving last match: %w", err)
+	}
+
+	if lastMatchData == nil {
+		return nil
+	}
+
+	if err := b.storage.AddMatch(ctx, summonerUUID, lastMatchData); err != nil {
+		return err
+	}
+
+	queueType := riotapi.LeagueQueueType(queueID)
+	if queueType == "" {
+		return nil
+	}
+
+	// a stored rank is the baseline of the LP change of the next match, it must not skip a game not announced yet
+	previousRank, err := b.storage.GetPreviousRank(ctx, summonerUUID, queueType)
+	if err != nil {
+		return err
+	}
+	if previousRank != nil {
+		return nil
+	}
+
+	for _, entry := range leagueEntries {
+		if entry.QueueType == queueType {
+			return b.storage.UpdateLeagueEntry(ctx, summonerUUID, entry)
 		}
 	}
+
+	return nil
 }
 
 // handleRemove processes the /remove command for the Discord bot.
@@ -201,7 +301,11 @@ func (b *Bot) handleRemove(s *discordgo.Session, i *discordgo.InteractionCreate)
 		for _, summonerName := range summonerNames {
 			summonerName = strings.TrimSpace(summonerName)
 
-			err := b.storage.RemoveSummoner(guildID, summonerName)
+			// the summoner may have changed their Riot ID since they were added
+			currentName, err := b.storage.ResolveSummonerName(b.ctx, guildID, summonerName)
+			if err == nil {
+				err = b.storage.RemoveSummoner(guildID, currentName)
+			}
 			if err != nil {
 				if err == storage.ErrSummonerNotFound {
 					responses = append(responses, fmt.Sprintf("❌ Summoner '%s' was not found in the tracking list.", summonerName))
@@ -209,8 +313,10 @@ func (b *Bot) handleRemove(s *discordgo.Session, i *discordgo.InteractionCreate)
 					log.Printf("Error removing summoner '%s': %v", summonerName, err)
 					responses = append(responses, fmt.Sprintf("❌ An error occurred while removing '%s'. Please try again later.", summonerName))
 				}
+			} else if !strings.EqualFold(currentName, summonerName) {
+				responses = append(responses, fmt.Sprintf("✅ Summoner '%s' (now %s) has been removed from tracking in this server.", summonerName, currentName))
 			} else {
-				responses = append(responses, fmt.Sprintf("✅ Summoner '%s' has been removed from tracking in this server.", summonerName))
+				responses = append(responses, fmt.Sprintf("✅ Summoner '%s' has been removed from tracking in this server.", currentName))
 			}
 		}
 
@@ -265,36 +371,29 @@ func (b *Bot) handleList(s *discordgo.Session, i *discordgo.InteractionCreate) {
 			return
 		}
 
-		currentVersion, err := b.riotClient.GetCurrentDDragonVersion()
-		if err != nil {
-			log.Printf("Warning: %v", err)
-		}
-
 		embeds := []*discordgo.MessageEmbed{}
 
 		for _, summoner := range summoners {
 			var description string
 			var title string
 
-			profileIconImageURL := fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/profileicon/%d.png", currentVersion, summoner.ProfileIconID)
+			profileIconImageURL := b.ddragon.ProfileIconURL(summoner.ProfileIconID)
 
 			urlFormattedName := strings.ReplaceAll(summoner.Name, "#", "-")
-			leagueOfGraphLink := fmt.Sprintf("https://www.leagueofgraphs.com/summoner/euw/%s", url.PathEscape(urlFormattedName))
+			leagueOfGraphLink := fmt.Sprintf("https://www.leagueofgraphs.com/summoner/%s/%s", riotapi.LeagueOfGraphsRegion(summoner.Region), url.PathEscape(urlFormattedName))
 
 			rankParts := strings.Fields(summoner.Rank)
 			tier := rankParts[0]
 			colorHex := utils.GetRankColor(tier)
 			color := int(colorHex)
 
-			if summoner.Rank == "" || strings.ToUpper(summoner.Rank) == "UNRANKED" {
-				title = fmt.Sprintf("%s - %s", summoner.Name, summoner.Rank)
-				description = fmt.Sprintf("Level %d", summoner.SummonerLevel)
-			} else {
-				words := strings.Fields(summoner.Rank)
-				words[0] = utils.CapitalizeFirst(strings.ToLower(words[0]))
-				formattedRank := strings.Join(words, " ")
-				title = fmt.Sprintf("%s - %s (%dLP)", summoner.Name, formattedRank, summoner.LeaguePoints)
-				description = fmt.Sprintf("Level %d", summoner.SummonerLevel)
+			title = fmt.Sprintf("%s - %s", summoner.Name, formatListRank(summoner.Rank, summoner.LeaguePoints))
+			description = fmt.Sprintf("Level %d • %s", summoner.SummonerLevel, riotapi.PlatformDisplayName(summoner.Region))
+			if summoner.FlexRank != "" && strings.ToUpper(summoner.FlexRank) != "UNRANKED" {
+				description = fmt.Sprintf("%s • Flex: %s", description, formatListRank(summoner.FlexRank, summoner.FlexLeaguePoints))
+			}
+			if position := b.formatLadderPosition(summoner.Region, riotapi.QueueTypeRankedSolo, summoner.SummonerPUUID); position != "" {
+				description = fmt.Sprintf("%s • Ladder: %s", description, position)
 			}
 
 			embed := &discordgo.MessageEmbed{
@@ -302,9 +401,7 @@ func (b *Bot) handleList(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				URL:         leagueOfGraphLink,
 				Color:       color,
 				Description: description,
-				Thumbnail: &discordgo.MessageEmbedThumbnail{
-					URL: profileIconImageURL,
-				},
+				Thumbnail:   thumbnail(profileIconImageURL),
 			}
 
 			embeds = append(embeds, embed)
@@ -342,6 +439,286 @@ func (b *Bot) handleUnchannel(s *discordgo.Session, i *discordgo.InteractionCrea
 	}
 }
 
+// formatListRank formats a rank stored as "TIER DIVISION" for /list (e.g. "Gold II (42LP)", "Master (312LP)").
+func formatListRank(rank string, leaguePoints int) string {
+	if rank == "" || strings.ToUpper(rank) == "UNRANKED" {
+		return rank
+	}
+
+	words := strings.Fields(rank)
+	if utils.IsApexTier(words[0]) {
+		words = words[:1]
+	}
+	words[0] = utils.CapitalizeFirst(strings.ToLower(words[0]))
+
+	return fmt.Sprintf("%s (%dLP)", strings.Join(words, " "), leaguePoints)
+}
+
+// handleQueues processes the /queues command for the Discord bot.
+// It shows or changes the queues announced in the server, or for a single summoner of the server.
+func (b *Bot) handleQueues(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	optionMap := mapOptionsByName(i.ApplicationCommandData().Options)
+
+	option, ok := optionMap["queues"]
+	if !ok {
+		trackedQueues, err := b.storage.GetGuildTrackedQueues(i.GuildID)
+		if err != nil {
+			log.Printf("Error fetching tracked queues of guild %s: %v", i.GuildID, err)
+			respondWithError(s, i, "Something went wrong. Please try again later.")
+			return
+		}
+
+		message := fmt.Sprintf("Tracked queues: **%s**\nAvailable queues: %s", formatQueues(trackedQueues), strings.Join(riotapi.QueueAliases(), ", "))
+		if err := respondToInteractionWithSource(s, i, message); err != nil {
+			log.Printf("Error responding to interaction: %v", err)
+		}
+		return
+	}
+
+	var summonerName string
+	if summonerOption, ok := optionMap["summoner"]; ok {
+		summonerName = strings.TrimSpace(summonerOption.StringValue())
+
+		currentName, err := b.storage.ResolveSummonerName(b.ctx, i.GuildID, summonerName)
+		if err == storage.ErrSummonerNotFound {
+			respondWithError(s, i, fmt.Sprintf("Summoner '%s' was not found in the tracking list.", summonerName))
+			return
+		}
+		if err != nil {
+			log.Printf("Error resolving summoner name '%s': %v", summonerName, err)
+			respondWithError(s, i, "Something went wrong. Please try again later.")
+			return
+		}
+		summonerName = currentName
+	}
+
+	var queueIDs []int
+	if !strings.EqualFold(strings.TrimSpace(option.StringValue()), "default") {
+		for _, alias := range strings.Split(option.StringValue(), ",") {
+			queueID, err := riotapi.ParseQueue(alias)
+			if err != nil {
+				respondWithError(s, i, err.Error())
+				return
+			}
+			if !slices.Contains(queueIDs, queueID) {
+				queueIDs = append(queueIDs, queueID)
+			}
+		}
+	} else if summonerName == "" {
+		queueIDs = riotapi.DefaultTrackedQueues
+	}
+
+	previousQueues, err := b.storage.GetGuildTrackedQueues(i.GuildID)
+	if err != nil {
+		log.Printf("Error fetching tracked queues of guild %s: %v", i.GuildID, err)
+		respondWithError(s, i, "Something went wrong. Please try again later.")
+		return
+	}
+
+	var message string
+	if summonerName == "" {
+		err = b.storage.SetGuildTrackedQueues(i.GuildID, queueIDs)
+		message = fmt.Sprintf("✅ This server now tracks: **%s**", formatQueues(queueIDs))
+	} else {
+		err = b.storage.SetSummonerTrackedQueues(i.GuildID, summonerName, queueIDs)
+		message = fmt.Sprintf("✅ '%s' is now tracked in: **%s**", summonerName, formatQueues(queueIDs))
+		if queueIDs == nil {
+			message = fmt.Sprintf("✅ '%s' now follows the queues of this server: **%s**", summonerName, formatQueues(previousQueues))
+		}
+	}
+	if err != nil {
+		if err == storage.ErrSummonerNotFound {
+			respondWithError(s, i, fmt.Sprintf("Summoner '%s' was not found in the tracking list.", summonerName))
+			return
+		}
+		log.Printf("Error updating tracked queues of guild %s: %v", i.GuildID, err)
+		respondWithError(s, i, "Something went wrong. Please try again later.")
+		return
+	}
+
+	if err := respondToInteractionWithSource(s, i, message); err != nil {
+		log.Printf("Error responding to interaction: %v", err)
+	}
+
+	// a summoner going back to the queues of the server may not have been tracked in some of them
+	if summonerName != "" && queueIDs == nil {
+		queueIDs = previousQueues
+	}
+
+	var newQueues []int
+	for _, queueID := range queueIDs {
+		if !slices.Contains(previousQueues, queueID) || summonerName != "" {
+			newQueues = append(newQueues, queueID)
+		}
+	}
+
+	if len(newQueues) > 0 {
+		go b.storeLastMatches(i.GuildID, summonerName, newQueues)
+	}
+}
+
+// storeLastMatches stores the last match of newly tracked queues for the summoners of a guild
+// (or only summonerName when not empty), so the tracker doesn't announce games played before.
+// Queues that already have a stored match keep it, the tracker announces the games played since.
+func (b *Bot) storeLastMatches(guildID, summonerName string, queueIDs []int) {
+	ctx, cancel := context.WithTimeout(b.ctx, addSummonersTimeout)
+	defer cancel()
+
+	summoners, err := b.storage.ListSummoners(guildID)
+	if err != nil {
+		log.Printf("Error listing summoners of guild %s: %v", guildID, err)
+		return
+	}
+
+	for _, summoner := range summoners {
+		if summonerName != "" && !strings.EqualFold(summoner.Name, summonerName) {
+			continue
+		}
+
+		summonerUUID, err := b.storage.GetSummonerUUIDFromRiotID(ctx, summoner.RiotSummonerID)
+		if err != nil {
+			log.Printf("Error fetching summoner UUID of %s: %v", summoner.Name, err)
+			continue
+		}
+
+		var untrackedQueues []int
+		for _, queueID := range queueIDs {
+			storedMatchID, _, err := b.storage.GetLastMatch(ctx, summoner.SummonerPUUID, queueID)
+			if err != nil {
+				log.Printf("Error fetching last %s match of %s: %v", riotapi.QueueName(queueID), summoner.Name, err)
+				continue
+			}
+			if storedMatchID == "" {
+				untrackedQueues = append(untrackedQueues, queueID)
+			}
+		}
+
+		if len(untrackedQueues) == 0 {
+			continue
+		}
+
+		leagueEntries, err := b.riotClient.GetLeagueEntries(ctx, summoner.Region, summoner.SummonerPUUID)
+		if err != nil {
+			log.Printf("Error fetching rank of %s: %v", summoner.Name, err)
+			continue
+		}
+
+		entries := make([]*riotapi.LeagueEntry, 0, len(leagueEntries))
+		for idx := range leagueEntries {
+			entries = append(entries, &leagueEntries[idx])
+		}
+
+		for _, queueID := range untrackedQueues {
+			if err := b.storeLastMatch(ctx, summonerUUID, summoner.Region, summoner.SummonerPUUID, queueID, entries); err != nil {
+				log.Printf("Error storing last %s match of %s: %v", riotapi.QueueName(queueID), summoner.Name, err)
+			}
+		}
+	}
+}
+
+// masteryTopCount is the number of champions listed by the /mastery command.
+const masteryTopCount = 10
+
+// handleMastery processes the /mastery command for the Discord bot.
+// It lists the champions a summoner has the most mastery points on. The summoner doesn't need to be tracked,
+// tracked summoners are looked up on their own region.
+func (b *Bot) handleMastery(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	optionMap := mapOptionsByName(i.ApplicationCommandData().Options)
+	summonerName := strings.TrimSpace(optionMap["summoner"].StringValue())
+
+	gameName, tagLine, found := strings.Cut(summonerName, "#")
+	if !found {
+		respondWithError(s, i, fmt.Sprintf("Invalid format for '%s'. Use Name#Tag.", summonerName))
+		return
+	}
+
+	region := b.config.RiotAPIRegion
+	if option, ok := optionMap["region"]; ok {
+		normalized, err := riotapi.NormalizePlatform(option.StringValue())
+		if err != nil {
+			respondWithError(s, i, err.Error())
+			return
+		}
+		region = normalized
+	}
+
+	if err := respondToInteractionWithSource(s, i, fmt.Sprintf("Retrieving champion masteries of %s...", summonerName)); err != nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(b.ctx, addSummonersTimeout)
+		defer cancel()
+
+		// tracked summoners are also found by a former Riot ID
+		var puuid string
+		if currentName, err := b.storage.ResolveSummonerName(ctx, i.GuildID, summonerName); err == nil {
+			if summoners, err := b.storage.ListSummoners(i.GuildID); err == nil {
+				for _, summoner := range summoners {
+					if summoner.Name == currentName {
+						puuid, region, summonerName = summoner.SummonerPUUID, summoner.Region, summoner.Name
+						break
+					}
+				}
+			}
+		}
+
+		if puuid == "" {
+			account, err := b.riotClient.GetAccountPUUIDBySummonerName(ctx, region, strings.TrimSpace(gameName), strings.TrimSpace(tagLine))
+			if err != nil {
+				sendFollowUpMessage(s, i, fmt.Sprintf("❌ Unable to find '%s': %v", summonerName, err))
+				return
+			}
+			puuid = account.SummonerPUUID
+			summonerName = fmt.Sprintf("%s#%s", account.SummonerName, account.SummonerTagLine)
+		}
+
+		masteries, err := b.riotClient.GetTopChampionMasteries(ctx, region, puuid, masteryTopCount)
+		if err != nil {
+			log.Printf("Error fetching champion masteries of %s: %v", summonerName, err)
+			sendFollowUpMessage(s, i, "An error occurred while retrieving champion masteries. Please try again later.")
+			return
+		}
+
+		if len(masteries) == 0 {
+			sendFollowUpMessage(s, i, fmt.Sprintf("%s hasn't played any champion yet.", summonerName))
+			return
+		}
+
+		embed := &discordgo.MessageEmbed{
+			Title:       fmt.Sprintf("%s - Top champions", summonerName),
+			Color:       0xC89B3C,
+			Description: b.formatTopMasteries(masteries),
+			Thumbnail:   thumbnail(b.ddragon.ChampionImageURL(b.ddragon.ChampionByKey(masteries[0].ChampionID))),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: riotapi.PlatformDisplayName(region),
+			},
+		}
+
+		sendFollowUpMessage(s, i, "", embed)
+	}()
+}
+
+// formatQueues returns the comma-separated short names of queue ids (e.g. "solo, flex").
+func formatQueues(queueIDs []int) string {
+	aliases := make([]string, 0, len(queueIDs))
+	for _, queueID := range queueIDs {
+		aliases = append(aliases, riotapi.QueueAlias(queueID))
+	}
+
+	return strings.Join(aliases, ", ")
+}
+
+// mapOptionsByName indexes the options of a command by their name, so optional options can be looked up.
+func mapOptionsByName(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, option := range options {
+		optionMap[option.Name] = option
+	}
+
+	return optionMap
+}
+
 // respondWithError generates an ephemeral error message that is only shown to the user that typed a command
 func respondWithError(s *discordgo.Session, i *discordgo.InteractionCreate, message string) {
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// trackerLeaseName is the name of the lease held by the instance running the trackers, across every shard.
+	trackerLeaseName = "tracker"
+	// leaseTTL is how long a lease lasts without renewal. It is shorter than trackMatchesInterval,
+	// so a standby instance takes over within one polling interval when the holder dies.
+	leaseTTL = 90 * time.Second
+	// leaseRenewInterval is how often holders renew their leases and standby instances try to take them.
+	leaseRenewInterval = 30 * time.Second
+	// leaseTimeout bounds a single renewal.
+	leaseTimeout = 10 * time.Second
+)
+
+// errNotLeader is returned by the writes and announcements of the trackers once this instance lost the tracker lease.
+var errNotLeader = errors.New("this instance doesn't hold the tracker lease anymore")
+
+// lease is a lease of the leases table this instance tries to hold.
+type lease struct {
+	name string
+	held atomic.Bool
+	// renewedAt is when the last successful renewal was sent, in Unix nanoseconds
+	renewedAt atomic.Int64
+}
+
+// valid reports whether this instance holds the lease and it can't have expired yet. A check running when the
+// lease is lost stops writing and announcing before another instance can take over.
+func (l *lease) valid() bool {
+	return l.held.Load() && time.Since(time.Unix(0, l.renewedAt.Load())) < leaseTTL
+}
+
+// shardLeaseName returns the name of the lease held by the instance answering the interactions of a shard.
+func shardLeaseName(shardID int) string {
+	return fmt.Sprintf("shard-%d", shardID)
+}
+
+// newInstanceID returns an ID identifying this bot instance in the leases table, readable in logs.
+func newInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8])
+}
+
+// isLeader reports whether this instance holds the tracker lease. Only the leader polls Riot and posts
+// announcements, in every guild whatever their shard, the other instances stand by in case it dies.
+func (b *Bot) isLeader() bool {
+	return b.trackerLease.valid()
+}
+
+// answersInteractions reports whether this instance holds the lease of its shard. Discord sends the events of
+// a shard to every instance connected as that shard, only the holder answers them.
+func (b *Bot) answersInteractions() bool {
+	return b.shardLease.held.Load()
+}
+
+// RunLeaderElection renews the leases of this instance, or tries to take them, until the bot context is cancelled.
+// The leases are released on return so standby instances take over right away.
+func (b *Bot) RunLeaderElection() {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			log.Println("Stopping leader election")
+
+			ctx, cancel := context.WithTimeout(context.Background(), leaseTimeout)
+			defer cancel()
+
+			for _, l := range []*lease{&b.trackerLease, &b.shardLease} {
+				if err := b.storage.ReleaseLease(ctx, l.name, b.instanceID); err != nil {
+					log.Printf("Error releasing %s lease: %v", l.name, err)
+				}
+			}
+			return
+		case <-ticker.C:
+			b.renewLeases()
+		}
+	}
+}
+
+// renewLeases renews or takes the tracker lease and the lease of the shard.
+func (b *Bot) renewLeases() {
+	trackerGained := b.renewLease(&b.trackerLease)
+	shardGained := b.renewLease(&b.shardLease)
+
+	// the previous holder had the apex ladders, this instance has none or outdated ones
+	if trackerGained || shardGained {
+		b.requestApexLadderRefresh()
+	}
+}
+
+// renewLease renews or takes a lease, logs when this instance gains or loses it and reports whether it was just
+// gained. A holder that can't reach the database gives it up once it expired, another instance may have it by then.
+func (b *Bot) renewLease(l *lease) bool {
+	ctx, cancel := context.WithTimeout(b.ctx, leaseTimeout)
+	defer cancel()
+
+	// the lease runs from the database clock at some point of the request, counting from before it is safe
+	sentAt := time.Now()
+
+	acquired, err := b.storage.AcquireLease(ctx, l.name, b.instanceID, leaseTTL)
+	if err != nil {
+		log.Printf("Error renewing %s lease: %v", l.name, err)
+		if l.valid() {
+			return false
+		}
+		acquired = false
+	}
+
+	if acquired {
+		l.renewedAt.Store(sentAt.UnixNano())
+	}
+
+	if l.held.Swap(acquired) == acquired {
+		return false
+	}
+
+	if acquired {
+		log.Printf("Instance %s now holds the %s lease", b.instanceID, l.name)
+	} else {
+		log.Printf("Instance %s lost the %s lease, standing by", b.instanceID, l.name)
+	}
+
+	return acquired
+}
@@ -0,0 +1,374 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	dg "github.com/bwmarrin/discordgo"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+const (
+	// liveGamePollInterval is how often tracked summoners are checked for a game in progress.
+	liveGamePollInterval = 2 * time.Minute
+	// liveGameMessageTTL is how long a live game announcement waits for its match result to reply to it.
+	liveGameMessageTTL = 6 * time.Hour
+	// liveGameOverFooter replaces the footer of a live game announcement once its result is posted.
+	liveGameOverFooter = "Game over • result below"
+	// liveRankCacheTTL is how long the ranks of the players of live games are reused before being fetched again.
+	liveRankCacheTTL = 30 * time.Minute
+)
+
+// liveGameState is what TrackLiveGames remembers between polls. It is only used by its goroutine.
+type liveGameState struct {
+	// checkedAt maps the puuid of every tracked summoner to when they were last looked for in a game
+	checkedAt map[string]time.Time
+	// playing maps the puuid of every tracked summoner seen in game to the id of their match
+	playing map[string]string
+	// ranks caches the league entries of the players of live games by puuid
+	ranks map[string]cachedLeagueEntries
+}
+
+// cachedLeagueEntries are the league entries of a player and when they were fetched.
+type cachedLeagueEntries struct {
+	entries   []riotapi.LeagueEntry
+	fetchedAt time.Time
+}
+
+func newLiveGameState() *liveGameState {
+	return &liveGameState{
+		checkedAt: make(map[string]time.Time),
+		playing:   make(map[string]string),
+		ranks:     make(map[string]cachedLeagueEntries),
+	}
+}
+
+// due reports whether a summoner should be looked for in a game at now. Like match tracking, summoners are
+// checked less often the longer they have been idle, see pollInterval.
+func (l *liveGameState) due(summoner s.SummonerWithGuilds, now time.Time) bool {
+	checkedAt, ok := l.checkedAt[summoner.Summoner.SummonerPUUID]
+	if !ok {
+		return true
+	}
+
+	interval := max(pollInterval(now.Sub(summoner.LastActiveAt)), liveGamePollInterval)
+	return now.Sub(checkedAt) >= interval
+}
+
+// forget drops what is remembered about summoners that are no longer tracked, and the expired ranks.
+func (l *liveGameState) forget(tracked map[string]bool, now time.Time) {
+	for puuid := range l.checkedAt {
+		if !tracked[puuid] {
+			delete(l.checkedAt, puuid)
+			delete(l.playing, puuid)
+		}
+	}
+
+	for puuid, cached := range l.ranks {
+		if now.Sub(cached.fetchedAt) >= liveRankCacheTTL {
+			delete(l.ranks, puuid)
+		}
+	}
+}
+
+// TrackLiveGames continuously checks whether tracked summoners are playing a game in a queue tracked for them,
+// and announces each new game once per guild. The match result posted later by TrackMatches replies to it.
+func (b *Bot) TrackLiveGames() {
+	ticker := time.NewTicker(liveGamePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			log.Println("Stopping live game tracking")
+			return
+		case <-ticker.C:
+			b.checkLiveGames()
+		}
+	}
+}
+
+// checkLiveGames looks for a game in progress for every tracked summoner due for it.
+func (b *Bot) checkLiveGames() {
+	if !b.isLeader() || b.riotClient.KeyInvalid() {
+		return
+	}
+
+	if err := b.storage.DeleteStaleLiveGameMessages(b.ctx, time.Now().Add(-liveGameMessageTTL)); err != nil {
+		log.Printf("Error deleting stale live game messages: %v", err)
+	}
+
+	summoners, err := b.storage.GetAllSummonersWithGuilds(b.ctx)
+	if err != nil {
+		log.Printf("Error fetching summoners: %v", err)
+		return
+	}
+
+	now := time.Now()
+	tracked := make(map[string]bool, len(summoners))
+	for _, summoner := range summoners {
+		tracked[summoner.Summoner.SummonerPUUID] = true
+	}
+	b.liveGames.forget(tracked, now)
+
+	// several tracked summoners can be in the same game, its embed is only built once
+	embeds := make(map[string]*dg.MessageEmbed)
+
+	for _, summoner := range summoners {
+		if b.ctx.Err() != nil || b.riotClient.KeyInvalid() {
+			return
+		}
+
+		if !b.liveGames.due(summoner, now) {
+			continue
+		}
+		b.liveGames.checkedAt[summoner.Summoner.SummonerPUUID] = now
+
+		ctx, cancel := context.WithTimeout(b.ctx, summonerCheckTimeout)
+		err := b.checkLiveGame(ctx, summoner, embeds)
+		cancel()
+		if err != nil {
+			log.Printf("Error checking live game for %s: %v", summoner.Summoner.Name, err)
+		}
+	}
+}
+
+// checkLiveGame announces the game a summoner is playing in every guild tracking them in that queue
+// that didn't announce it yet.
+func (b *Bot) checkLiveGame(ctx context.Context, summoner s.SummonerWithGuilds, embeds map[string]*dg.MessageEmbed) error {
+	puuid := summoner.Summoner.SummonerPUUID
+
+	game, err := b.riotClient.GetActiveGame(ctx, summoner.Summoner.Region, puuid)
+	if err != nil {
+		return fmt.Errorf("error fetching active game: %w", err)
+	}
+
+	if game == nil {
+		if matchID, ok := b.liveGames.playing[puuid]; ok {
+			delete(b.liveGames.playing, puuid)
+			log.Printf("%s is no longer in game (%s)", summoner.Summoner.Name, matchID)
+		}
+		return nil
+	}
+
+	matchID := game.MatchID()
+
+	if b.liveGames.playing[puuid] != matchID {
+		b.liveGames.playing[puuid] = matchID
+		log.Printf("%s is in game (%s)", summoner.Summoner.Name, matchID)
+
+		// the match will be looked for at the next tick of match tracking, and often while they keep playing
+		if err := b.storage.MarkSummonersActive(ctx, []string{puuid}); err != nil {
+			log.Printf("Error marking %s as active: %v", summoner.Summoner.Name, err)
+		}
+	}
+
+	guildIDs := summoner.GuildsTrackingQueue(game.GameQueueConfigID)
+	if len(guildIDs) == 0 {
+		return nil
+	}
+
+	for _, guildID := range guildIDs {
+		liveMessage, err := b.storage.GetLiveGameMessage(ctx, guildID, matchID)
+		if err != nil {
+			return err
+		}
+
+		if liveMessage != nil {
+			continue
+		}
+
+		embed, ok := embeds[matchID]
+		if !ok {
+			embed = b.prepareLiveGameEmbed(ctx, summoner.Summoner, game)
+			embeds[matchID] = embed
+		}
+
+		message, err := b.sendGuildEmbed(guildID, embed)
+		if err != nil {
+			log.Printf("Error announcing live game of %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+			continue
+		}
+
+		if err := b.storage.AddLiveGameMessage(ctx, guildID, matchID, message.ChannelID, message.ID); err != nil {
+			log.Printf("Error storing live game message of %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+		}
+	}
+
+	return nil
+}
+
+// prepareLiveGameEmbed returns an embed announcing the game a summoner is playing,
+// with both teams and the rank of every player.
+func (b *Bot) prepareLiveGameEmbed(ctx context.Context, summoner riotapi.Summoner, game *riotapi.ActiveGame) *dg.MessageEmbed {
+	var blueTeam, redTeam []string
+	for _, participant := range game.Participants {
+		line := b.formatLiveParticipant(ctx, summoner.Region, riotapi.LeagueQueueType(game.GameQueueConfigID), participant)
+		if participant.PUUID == summoner.SummonerPUUID {
+			line = fmt.Sprintf("**%s**", line)
+		}
+
+		if participant.TeamID == 100 {
+			blueTeam = append(blueTeam, line)
+		} else {
+			redTeam = append(redTeam, line)
+		}
+	}
+
+	embed := &dg.MessageEmbed{
+		Title: fmt.Sprintf("🔴 %s is in game", summoner.Name),
+		Color: 0xE91E63,
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:   "Blue team",
+				Value:  strings.Join(blueTeam, "\n"),
+				Inline: false,
+			},
+			{
+				Name:   "Red team",
+				Value:  strings.Join(redTeam, "\n"),
+				Inline: false,
+			},
+		},
+		Footer: &dg.MessageEmbedFooter{
+			Text: formatGameLength(game),
+		},
+	}
+
+	if tracked := game.FindParticipant(summoner.SummonerPUUID); tracked != nil {
+		champion := b.ddragon.ChampionByKey(tracked.ChampionID)
+		embed.Description = fmt.Sprintf("Playing **%s** in %s", champion.Name, riotapi.QueueName(game.GameQueueConfigID))
+		embed.Thumbnail = thumbnail(b.ddragon.ChampionImageURL(champion))
+	}
+
+	return embed
+}
+
+// formatLiveParticipant returns "Champion • Name#Tag (Gold II 50LP)" for a player of a live game.
+// The rank shown is the one of queueType, or the solo/duo one for games of unranked queues.
+func (b *Bot) formatLiveParticipant(ctx context.Context, region, queueType string, participant riotapi.ActiveGameParticipant) string {
+	champion := b.ddragon.ChampionByKey(participant.ChampionID)
+
+	if queueType == "" {
+		queueType = riotapi.QueueTypeRankedSolo
+	}
+
+	rank := "?"
+	if participant.PUUID != "" {
+		leagueEntries, err := b.liveParticipantLeagueEntries(ctx, region, participant.PUUID)
+		rankInfo := riotapi.FindLeagueEntry(leagueEntries, queueType)
+		if err != nil {
+			log.Printf("Error fetching rank of %s: %v", participant.RiotID, err)
+		} else if rankInfo.Tier == "UNRANKED" {
+			rank = "Unranked"
+		} else {
+			rank = fmt.Sprintf("%s %s %dLP", u.CapitalizeFirst(strings.ToLower(rankInfo.Tier)), rankInfo.Rank, rankInfo.LeaguePoints)
+		}
+	}
+
+	return fmt.Sprintf("%s • %s (%s)", champion.Name, participant.RiotID, rank)
+}
+
+// liveParticipantLeagueEntries returns the league entries of a player of a live game, fetched at most once
+// per liveRankCacheTTL since tracked summoners often play several games in a row with the same players.
+func (b *Bot) liveParticipantLeagueEntries(ctx context.Context, region, puuid string) ([]riotapi.LeagueEntry, error) {
+	if cached, ok := b.liveGames.ranks[puuid]; ok && time.Since(cached.fetchedAt) < liveRankCacheTTL {
+		return cached.entries, nil
+	}
+
+	leagueEntries, err := b.riotClient.GetLeagueEntries(ctx, region, puuid)
+	if err != nil {
+		return nil, err
+	}
+
+	b.liveGames.ranks[puuid] = cachedLeagueEntries{entries: leagueEntries, fetchedAt: time.Now()}
+
+	return leagueEntries, nil
+}
+
+// formatGameLength returns how long a live game has been running.
+func formatGameLength(game *riotapi.ActiveGame) string {
+	if game.GameStartTime == 0 {
+		return "In loading screen"
+	}
+
+	elapsed := time.Since(time.UnixMilli(game.GameStartTime))
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return fmt.Sprintf("In game for %d:%02d", int(elapsed.Minutes()), int(elapsed.Seconds())%60)
+}
+
+// announceMatchResult sends the result of a match to a guild and returns the sent message. When the guild announced
+// the match while it was being played, the result replies to that announcement instead of being posted on its own.
+func (b *Bot) announceMatchResult(guildID, matchID string, embed *dg.MessageEmbed) (*dg.Message, error) {
+	liveMessage, err := b.storage.GetLiveGameMessage(b.ctx, guildID, matchID)
+	if err != nil {
+		log.Printf("Error fetching live game message for %s in guild %s: %v", matchID, guildID, err)
+	}
+
+	if liveMessage == nil {
+		return b.sendGuildEmbed(guildID, embed)
+	}
+
+	if !b.isLeader() {
+		return nil, errNotLeader
+	}
+
+	failIfNotExists := false
+	var message *dg.Message
+	err = u.RetryWithBackoff(b.ctx, func() error {
+		message, err = b.session.ChannelMessageSendComplex(liveMessage.ChannelID, &dg.MessageSend{
+			Embeds: []*dg.MessageEmbed{embed},
+			Reference: &dg.MessageReference{
+				MessageID:       liveMessage.MessageID,
+				ChannelID:       liveMessage.ChannelID,
+				GuildID:         liveMessage.GuildID,
+				FailIfNotExists: &failIfNotExists,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error sending embed reply to channel %s: %w", liveMessage.ChannelID, err)
+		}
+		return nil
+	}, u.DefaultRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	b.markLiveGameOver(liveMessage)
+
+	return message, nil
+}
+
+// markLiveGameOver updates a live game announcement to show that its result was posted.
+func (b *Bot) markLiveGameOver(liveMessage *s.LiveGameMessage) {
+	message, err := b.session.ChannelMessage(liveMessage.ChannelID, liveMessage.MessageID)
+	if err != nil {
+		log.Printf("Error fetching live game message %s: %v", liveMessage.MessageID, err)
+		return
+	}
+
+	if len(message.Embeds) == 0 {
+		return
+	}
+
+	liveEmbed := message.Embeds[0]
+	if liveEmbed.Footer != nil && liveEmbed.Footer.Text == liveGameOverFooter {
+		return // another tracked summoner of the same game already ended it
+	}
+
+	liveEmbed.Title = strings.Replace(liveEmbed.Title, "🔴", "⚫", 1)
+	liveEmbed.Color = 0x808080
+	liveEmbed.Footer = &dg.MessageEmbedFooter{Text: liveGameOverFooter}
+
+	if _, err := b.session.ChannelMessageEditEmbed(liveMessage.ChannelID, liveMessage.MessageID, liveEmbed); err != nil {
+		log.Printf("Error updating live game message %s: %v", liveMessage.MessageID, err)
+	}
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+// masteryMilestones are the mastery points announced when a summoner reaches them on a champion.
+var masteryMilestones = []int{100_000, 500_000, 1_000_000}
+
+// masteryUpdate is a change of mastery on a champion worth announcing.
+type masteryUpdate struct {
+	previous riotapi.ChampionMastery
+	current  riotapi.ChampionMastery
+	// milestone is the highest milestone crossed, 0 if none
+	milestone int
+}
+
+// checkMasteryUpdates snapshots the champion masteries of a summoner and announces level-ups and point milestones.
+// Masteries only change after a game, they're only fetched when the summoner played (or was never snapshotted),
+// and the first snapshot of a summoner is stored without announcement.
+func (b *Bot) checkMasteryUpdates(ctx context.Context, summoner s.SummonerWithGuilds, summonerUUID uuid.UUID, played bool) error {
+	storedMasteries, err := b.storage.GetChampionMasteries(ctx, summonerUUID)
+	if err != nil {
+		return err
+	}
+
+	if len(storedMasteries) > 0 && !played {
+		return nil
+	}
+
+	masteries, err := b.riotClient.GetChampionMasteries(ctx, summoner.Summoner.Region, summoner.Summoner.SummonerPUUID)
+	if err != nil {
+		return fmt.Errorf("error fetching champion masteries: %w", err)
+	}
+
+	var updates []masteryUpdate
+	var changed []riotapi.ChampionMastery
+	for _, mastery := range masteries {
+		previous, ok := storedMasteries[mastery.ChampionID]
+		if ok && previous == mastery {
+			continue
+		}
+		changed = append(changed, mastery)
+
+		if len(storedMasteries) == 0 {
+			continue
+		}
+
+		update := masteryUpdate{previous: previous, current: mastery}
+		for _, milestone := range masteryMilestones {
+			if previous.ChampionPoints < milestone && mastery.ChampionPoints >= milestone {
+				update.milestone = milestone
+			}
+		}
+
+		if (ok && mastery.ChampionLevel > previous.ChampionLevel) || update.milestone > 0 {
+			updates = append(updates, update)
+		}
+	}
+
+	if err := b.storage.UpdateChampionMasteries(ctx, summonerUUID, changed); err != nil {
+		return err
+	}
+
+	for _, update := range updates {
+		embed := b.prepareMasteryEmbed(summoner.Summoner, update)
+
+		for _, guildID := range summoner.GuildIDs {
+			if err := b.announceNewMatch(guildID, embed); err != nil {
+				log.Printf("Error announcing mastery update for %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// prepareMasteryEmbed returns an embed announcing a mastery level-up or milestone of a summoner on a champion.
+func (b *Bot) prepareMasteryEmbed(summoner riotapi.Summoner, update masteryUpdate) *dg.MessageEmbed {
+	champion := b.ddragon.ChampionByKey(update.current.ChampionID)
+
+	var achievements []string
+	if update.previous.ChampionLevel > 0 && update.current.ChampionLevel > update.previous.ChampionLevel {
+		achievements = append(achievements, fmt.Sprintf("reached mastery level **%d**", update.current.ChampionLevel))
+	}
+	if update.milestone > 0 {
+		achievements = append(achievements, fmt.Sprintf("passed **%s** points", u.FormatThousands(update.milestone)))
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       fmt.Sprintf("%s • %s", summoner.Name, champion.Name),
+		Description: fmt.Sprintf("%s %s on **%s** 🎉", summoner.Name, strings.Join(achievements, " and "), champion.Name),
+		Color:       0xC89B3C,
+		Author: &dg.MessageEmbedAuthor{
+			Name: "Champion mastery",
+		},
+		Thumbnail: thumbnail(b.ddragon.ChampionImageURL(champion)),
+		Footer: &dg.MessageEmbedFooter{
+			Text: fmt.Sprintf("Level %d • %s points", update.current.ChampionLevel, u.FormatThousands(update.current.ChampionPoints)),
+		},
+	}
+
+	return embed
+}
+
+// formatTopMasteries returns one line per champion, e.g. "1. **Ahri** • Level 12 • 523,412 points".
+func (b *Bot) formatTopMasteries(masteries []riotapi.ChampionMastery) string {
+	lines := make([]string, 0, len(masteries))
+	for idx, mastery := range masteries {
+		champion := b.ddragon.ChampionByKey(mastery.ChampionID)
+		lines = append(lines, fmt.Sprintf("%d. **%s** • Level %d • %s points", idx+1, champion.Name, mastery.ChampionLevel, u.FormatThousands(mastery.ChampionPoints)))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,298 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dg "github.com/bwmarrin/discordgo"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+const (
+	// civilWarColor is the color of the announcement of a match tracked summoners played against each other.
+	civilWarColor = 0x9B59B6
+	// matchGroupTTL is how long the announcements of a match are kept to merge the results of the other tracked
+	// summoners who played it, who are checked at the next tick of match tracking or once match-v5 lists it.
+	matchGroupTTL = 2 * time.Hour
+)
+
+// matchResult is the announcement of a match for one tracked summoner.
+type matchResult struct {
+	// summoner holds the guilds to announce the match in
+	summoner s.SummonerWithGuilds
+	match    *riotapi.MatchData
+	embed    *dg.MessageEmbed
+	// followUps are announced after the match in the same guilds, e.g. a promotion or a streak
+	followUps []*dg.MessageEmbed
+}
+
+// teamID returns the team the summoner of the result played on.
+func (r matchResult) teamID() int {
+	for _, participant := range r.match.Participants {
+		if participant.PUUID == r.summoner.Summoner.SummonerPUUID {
+			return participant.TeamID
+		}
+	}
+
+	return 0
+}
+
+// matchGroup holds the announcements of a match in every guild, so the results of the tracked summoners who played
+// it are merged into one message. Its lock is held while the match is announced.
+type matchGroup struct {
+	mu        sync.Mutex
+	createdAt time.Time
+	guilds    map[string]*guildMatchAnnouncement
+	// coPlayersMarked is set once the other tracked summoners of the match were made due for a check
+	coPlayersMarked bool
+}
+
+// guildMatchAnnouncement is the message announcing a match in a guild, and the results it shows.
+type guildMatchAnnouncement struct {
+	message *dg.Message
+	results []matchResult
+}
+
+// matchGroups holds the groups of the matches announced recently, by match id.
+type matchGroups struct {
+	mu     sync.Mutex
+	groups map[string]*matchGroup
+}
+
+func newMatchGroups() *matchGroups {
+	return &matchGroups{groups: make(map[string]*matchGroup)}
+}
+
+// get returns the group of a match, a new one when it wasn't announced in the last matchGroupTTL.
+func (mg *matchGroups) get(matchID string) *matchGroup {
+	mg.mu.Lock()
+	defer mg.mu.Unlock()
+
+	for id, group := range mg.groups {
+		if time.Since(group.createdAt) >= matchGroupTTL {
+			delete(mg.groups, id)
+		}
+	}
+
+	group, ok := mg.groups[matchID]
+	if !ok {
+		group = &matchGroup{createdAt: time.Now(), guilds: make(map[string]*guildMatchAnnouncement)}
+		mg.groups[matchID] = group
+	}
+
+	return group
+}
+
+// announceMatch announces the result of a match for a summoner in the guilds tracking them, followed by
+// followUps, which are nil when there is none. When other tracked summoners played the match, their results are
+// merged in the same message: a duo or premade announcement when they were on the same team, a civil war
+// announcement when they played against each other. The first one announced is edited when the next ones are
+// checked, and the tracked summoners who played the match are made due for a check.
+func (b *Bot) announceMatch(ctx context.Context, summoner s.SummonerWithGuilds, match *riotapi.MatchData, embed *dg.MessageEmbed, followUps ...*dg.MessageEmbed) {
+	result := matchResult{summoner: summoner, match: match, embed: embed}
+	for _, followUp := range followUps {
+		if followUp != nil {
+			result.followUps = append(result.followUps, followUp)
+		}
+	}
+
+	group := b.matchGroups.get(match.MatchID)
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	for _, guildID := range summoner.GuildIDs {
+		// another instance may have announced it when the tracker lease moved during the check
+		claimed, err := b.storage.ClaimMatchAnnouncement(ctx, guildID, match.MatchID, summoner.Summoner.SummonerPUUID)
+		if err != nil {
+			log.Printf("Error claiming announcement of match %s for %s in guild %s: %v", match.MatchID, summoner.Summoner.Name, guildID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := b.announceGroupedMatch(guildID, group, result); err != nil {
+			log.Printf("Error announcing match %s for %s in guild %s: %v", match.MatchID, summoner.Summoner.Name, guildID, err)
+		}
+
+		for _, followUp := range result.followUps {
+			if err := b.announceNewMatch(guildID, followUp); err != nil {
+				log.Printf("Error announcing %q for %s in guild %s: %v", followUp.Title, summoner.Summoner.Name, guildID, err)
+			}
+		}
+	}
+
+	if !group.coPlayersMarked {
+		group.coPlayersMarked = true
+		b.markCoPlayersActive(ctx, summoner, match)
+	}
+}
+
+// announceGroupedMatch announces the result of a match in a guild. When the match was already announced there for
+// other tracked summoners, that message is edited to merge the result in. group.mu must be held.
+func (b *Bot) announceGroupedMatch(guildID string, group *matchGroup, result matchResult) error {
+	announcement := group.guilds[guildID]
+	if announcement == nil {
+		message, err := b.announceMatchResult(guildID, result.match.MatchID, result.embed)
+		if err != nil {
+			return err
+		}
+
+		group.guilds[guildID] = &guildMatchAnnouncement{message: message, results: []matchResult{result}}
+		return nil
+	}
+
+	if !b.isLeader() {
+		return errNotLeader
+	}
+
+	results := append(slices.Clone(announcement.results), result)
+	embed := b.prepareGroupedMatchEmbed(results)
+
+	err := u.RetryWithBackoff(b.ctx, func() error {
+		_, err := b.session.ChannelMessageEditEmbed(announcement.message.ChannelID, announcement.message.ID, embed)
+		if err != nil {
+			return fmt.Errorf("error editing message %s: %w", announcement.message.ID, err)
+		}
+		return nil
+	}, u.DefaultRetryConfig)
+	if err != nil {
+		return err
+	}
+
+	announcement.results = results
+	log.Printf("Match %s announced once for %d tracked summoners in guild %s", result.match.MatchID, len(results), guildID)
+
+	return nil
+}
+
+// markCoPlayersActive makes the tracked summoners who played a match with summoner due for a check, so their
+// result is merged in the announcement of the match without waiting for their next scheduled check.
+func (b *Bot) markCoPlayersActive(ctx context.Context, summoner s.SummonerWithGuilds, match *riotapi.MatchData) {
+	var puuids []string
+	for _, participant := range match.Participants {
+		if participant.PUUID != summoner.Summoner.SummonerPUUID {
+			puuids = append(puuids, participant.PUUID)
+		}
+	}
+
+	if err := b.storage.MarkSummonersActive(ctx, puuids); err != nil {
+		log.Printf("Error marking the players of match %s active: %v", match.MatchID, err)
+	}
+}
+
+// prepareGroupedMatchEmbed returns a single embed for the results of tracked summoners who played the same match,
+// each one shown with their KDA and LP change. Summoners of both teams make it a civil war, the winners listed first.
+func (b *Bot) prepareGroupedMatchEmbed(results []matchResult) *dg.MessageEmbed {
+	match := results[0].match
+
+	sorted := slices.Clone(results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].match.Win && !sorted[j].match.Win
+	})
+
+	var winners, losers []string
+	civilWar := false
+	for _, result := range sorted {
+		if result.teamID() != sorted[0].teamID() {
+			civilWar = true
+		}
+		if result.match.Win {
+			winners = append(winners, result.summoner.Summoner.Name)
+		} else {
+			losers = append(losers, result.summoner.Summoner.Name)
+		}
+	}
+
+	var title, description string
+	color := getEmbedColor(match.Result, match.GameDuration)
+	switch {
+	case civilWar && isRemake(match):
+		title = fmt.Sprintf("⚔️ Civil war: %s vs %s", joinNames(winners), joinNames(losers))
+		description = "Remake, nobody won this one"
+		color = civilWarColor
+	case civilWar:
+		title = fmt.Sprintf("⚔️ Civil war: %s vs %s", joinNames(winners), joinNames(losers))
+		description = fmt.Sprintf("%s won the bragging rights 🏆", joinNames(winners))
+		color = civilWarColor
+	case len(sorted) == 2:
+		title = fmt.Sprintf("🤝 Duo: %s", joinNames(append(winners, losers...)))
+		description = matchOutcome(match)
+	default:
+		title = fmt.Sprintf("🤝 %d-stack: %s", len(sorted), joinNames(append(winners, losers...)))
+		description = matchOutcome(match)
+	}
+
+	fields := make([]*dg.MessageEmbedField, 0, len(sorted))
+	for _, result := range sorted {
+		name := strings.Trim(result.embed.Title, "*")
+		if civilWar {
+			name = fmt.Sprintf("%s %s", teamEmoji(result.teamID()), name)
+		}
+
+		value := result.embed.Description
+		for _, field := range result.embed.Fields {
+			if field.Name == "Streak" {
+				value = fmt.Sprintf("%s\n%s", value, field.Value)
+			}
+		}
+
+		fields = append(fields, &dg.MessageEmbedField{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return &dg.MessageEmbed{
+		Title:       title,
+		URL:         leagueOfGraphsMatchURL(match.MatchID),
+		Description: description,
+		Color:       color,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
+		},
+		Thumbnail: thumbnail(b.ddragon.ProfileIconURL(sorted[0].summoner.Summoner.ProfileIconID)),
+		Fields:    fields,
+		Footer: &dg.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d:%02d • %s", match.GameDuration/60, match.GameDuration%60, u.FormatTime(match.GameEndTimestamp)),
+		},
+	}
+}
+
+// matchOutcome returns how a match ended for a team, e.g. "Victory together 🎉".
+func matchOutcome(match *riotapi.MatchData) string {
+	switch {
+	case isRemake(match):
+		return "Remake"
+	case match.Win:
+		return "Victory together 🎉"
+	default:
+		return "Defeat together 💀"
+	}
+}
+
+// teamEmoji returns the color of a team of Summoner's Rift, 100 being blue and 200 red.
+func teamEmoji(teamID int) string {
+	if teamID == 200 {
+		return "🔴"
+	}
+
+	return "🔵"
+}
+
+// joinNames returns "A", "A & B" or "A, B & C".
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+
+	return fmt.Sprintf("%s & %s", strings.Join(names[:len(names)-1], ", "), names[len(names)-1])
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tristan-derez/league-tracker/internal/ddragon"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+)
+
+// groupedMatchRoster holds the tracked players of EUW1_7000000001, won by the blue team (100).
+var groupedMatchRoster = []riotapi.MatchParticipant{
+	{PUUID: "puuid-blue-top", ChampionName: "Garen", TeamID: 100, TeamPosition: "TOP"},
+	{PUUID: "puuid-blue-jungle", ChampionName: "LeeSin", TeamID: 100, TeamPosition: "JUNGLE"},
+	{PUUID: "puuid-blue-middle", ChampionName: "Ahri", TeamID: 100, TeamPosition: "MIDDLE"},
+	{PUUID: "puuid-red-middle", ChampionName: "Syndra", TeamID: 200, TeamPosition: "MIDDLE"},
+}
+
+// newMatchResult returns the result of a tracked player of EUW1_7000000001, as announced on its own,
+// the match ending as a remake when remake is set.
+func newMatchResult(b *Bot, name, puuid string, remake bool) matchResult {
+	match := &riotapi.MatchData{
+		MatchID:      "EUW1_7000000001",
+		GameDuration: 1260,
+		QueueID:      riotapi.QueueRankedSolo,
+		Kills:        7,
+		Deaths:       1,
+		Assists:      6,
+		Result:       "Loss",
+		Participants: groupedMatchRoster,
+	}
+	if remake {
+		match.GameDuration = remakeMaxDuration - 60
+	}
+	for _, participant := range groupedMatchRoster {
+		if participant.PUUID == puuid {
+			match.ChampionName = participant.ChampionName
+			match.TeamPosition = participant.TeamPosition
+			match.Win = participant.TeamID == 100
+		}
+	}
+	if match.Win {
+		match.Result = "Win"
+	}
+
+	summoner := s.SummonerWithGuilds{
+		Summoner: riotapi.Summoner{Name: name, SummonerPUUID: puuid, Region: "euw1"},
+		GuildIDs: []string{"guild"},
+	}
+	rank := &riotapi.LeagueEntry{QueueType: riotapi.QueueTypeRankedSolo, Tier: "GOLD", Rank: "II", LeaguePoints: 50, Wins: 10, Losses: 10}
+
+	return matchResult{summoner: summoner, match: match, embed: b.prepareMatchEmbed(summoner.Summoner, match, rank, 0, 0, nil)}
+}
+
+func TestPrepareGroupedMatchEmbed(t *testing.T) {
+	b := &Bot{ddragon: ddragon.New(t.TempDir())}
+
+	blueMiddle := newMatchResult(b, "BlueMiddle#EUW", "puuid-blue-middle", false)
+	blueJungle := newMatchResult(b, "BlueJungle#EUW", "puuid-blue-jungle", false)
+	blueTop := newMatchResult(b, "BlueTop#EUW", "puuid-blue-top", false)
+	redMiddle := newMatchResult(b, "RedMiddle#EUW", "puuid-red-middle", false)
+	blueRemake := newMatchResult(b, "BlueMiddle#EUW", "puuid-blue-middle", true)
+	redRemake := newMatchResult(b, "RedMiddle#EUW", "puuid-red-middle", true)
+
+	tests := []struct {
+		name        string
+		results     []matchResult
+		title       string
+		description string
+		color       int
+		// fields are the names of the fields, in order
+		fields []string
+	}{
+		{
+			name:        "duo",
+			results:     []matchResult{blueJungle, blueMiddle},
+			title:       "🤝 Duo: BlueJungle#EUW & BlueMiddle#EUW",
+			description: "Victory together 🎉",
+			color:       0x00FF00,
+			fields:      []string{"BlueJungle#EUW (?LP)", "BlueMiddle#EUW (?LP)"},
+		},
+		{
+			name:        "premade",
+			results:     []matchResult{blueTop, blueJungle, blueMiddle},
+			title:       "🤝 3-stack: BlueTop#EUW, BlueJungle#EUW & BlueMiddle#EUW",
+			description: "Victory together 🎉",
+			color:       0x00FF00,
+			fields:      []string{"BlueTop#EUW (?LP)", "BlueJungle#EUW (?LP)", "BlueMiddle#EUW (?LP)"},
+		},
+		{
+			name:        "civil war, winners first",
+			results:     []matchResult{redMiddle, blueMiddle, blueJungle},
+			title:       "⚔️ Civil war: BlueMiddle#EUW & BlueJungle#EUW vs RedMiddle#EUW",
+			description: "BlueMiddle#EUW & BlueJungle#EUW won the bragging rights 🏆",
+			color:       civilWarColor,
+			fields:      []string{"🔵 BlueMiddle#EUW (?LP)", "🔵 BlueJungle#EUW (?LP)", "🔴 RedMiddle#EUW (?LP)"},
+		},
+		{
+			name:        "civil war remake",
+			results:     []matchResult{blueRemake, redRemake},
+			title:       "⚔️ Civil war: BlueMiddle#EUW vs RedMiddle#EUW",
+			description: "Remake, nobody won this one",
+			color:       civilWarColor,
+			fields:      []string{"🔵 BlueMiddle#EUW (Remake)", "🔴 RedMiddle#EUW (Remake)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embed := b.prepareGroupedMatchEmbed(tt.results)
+
+			if embed.Title != tt.title || embed.Description != tt.description || embed.Color != tt.color {
+				t.Errorf("prepareGroupedMatchEmbed() = %q %q %#x, want %q %q %#x", embed.Title, embed.Description, embed.Color, tt.title, tt.description, tt.color)
+			}
+			if embed.URL != leagueOfGraphsMatchURL("EUW1_7000000001") {
+				t.Errorf("prepareGroupedMatchEmbed() URL = %q, want the match", embed.URL)
+			}
+
+			var fields []string
+			for _, field := range embed.Fields {
+				fields = append(fields, field.Name)
+				if !strings.Contains(field.Value, " with **") {
+					t.Errorf("field %q = %q, want the KDA of the summoner", field.Name, field.Value)
+				}
+			}
+			if strings.Join(fields, "|") != strings.Join(tt.fields, "|") {
+				t.Errorf("prepareGroupedMatchEmbed() fields = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestMatchGroups(t *testing.T) {
+	groups := newMatchGroups()
+
+	first := groups.get("EUW1_7000000001")
+	if again := groups.get("EUW1_7000000001"); again != first {
+		t.Error("get() of the same match returned another group, the results of its players wouldn't be merged")
+	}
+	if other := groups.get("EUW1_7000000002"); other == first {
+		t.Error("get() of another match returned the same group")
+	}
+
+	// a match announced long ago starts over
+	first.createdAt = time.Now().Add(-matchGroupTTL)
+	if expired := groups.get("EUW1_7000000001"); expired == first {
+		t.Error("get() returned a group older than matchGroupTTL")
+	}
+	if len(groups.groups) != 2 {
+		t.Errorf("%d groups kept, want 2", len(groups.groups))
+	}
+}
+
+func TestJoinNames(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{names: nil, want: ""},
+		{names: []string{"A"}, want: "A"},
+		{names: []string{"A", "B"}, want: "A & B"},
+		{names: []string{"A", "B", "C"}, want: "A, B & C"},
+	}
+
+	for _, tt := range tests {
+		if got := joinNames(tt.names); got != tt.want {
+			t.Errorf("joinNames(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
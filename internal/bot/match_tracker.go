@@ -1,9 +1,13 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,10 +18,21 @@ import (
 	u "github.com/tristan-derez/league-tracker/internal/utils"
 )
 
+const (
+	// trackMatchesInterval is how often the summoners due for a check are checked for new matches.
+	trackMatchesInterval = 4 * time.Minute
+	// summonerCheckTimeout bounds the time spent checking a single summoner, retries included.
+	summonerCheckTimeout = 2 * time.Minute
+	// matchIndexGracePeriod is how long after the revision date match-v5 is waited for to list the games league-v4
+	// already counted, before a rank change without match is taken for a dodge or a correction.
+	matchIndexGracePeriod = 30 * time.Minute
+)
+
 // TrackMatches continuously monitors and tracks matches for all summoners across all guilds.
-// It runs indefinitely, periodically checking for new matches and announcing them to relevant guilds.
+// It runs until the bot context is cancelled, periodically checking for new matches and announcing them to relevant guilds.
+// Each tick only checks the summoners due for a check, see pollInterval.
 func (b *Bot) TrackMatches() {
-	ticker := time.NewTicker(4 * time.Minute)
+	ticker := time.NewTicker(trackMatchesInterval)
 	defer ticker.Stop()
 
 	for {
@@ -26,82 +41,325 @@ func (b *Bot) TrackMatches() {
 			log.Println("Stopping match tracking")
 			return
 		case <-ticker.C:
-			summoners, err := b.storage.GetAllSummonersWithGuilds()
+			if !b.isLeader() {
+				continue
+			}
+
+			b.checkSeasonRollover()
+
+			if b.riotClient.KeyInvalid() {
+				log.Print("Match tracking paused, the Riot API key is invalid")
+				continue
+			}
+
+			summoners, err := b.storage.GetDueSummonersWithGuilds(b.ctx, time.Now())
 			if err != nil {
 				log.Printf("Error fetching summoners: %v", err)
 				continue
 			}
 
+			// summoners of a platform whose match history is down stay due until it is back
+			paused := make(map[string]int)
+			summoners = slices.DeleteFunc(summoners, func(summoner s.SummonerWithGuilds) bool {
+				if !b.matchHistoryDown.has(summoner.Summoner.Region) {
+					return false
+				}
+				paused[summoner.Summoner.Region]++
+				return true
+			})
+			logPausedRegions(paused)
+
 			if len(summoners) == 0 {
-				log.Print("No summoner to track for now")
+				if len(paused) == 0 {
+					log.Print("No summoner due for a check for now")
+				}
 				continue
 			}
 
-			log.Printf("🕵️ Tracking matches for %d summoners", len(summoners))
-
-			for _, summoner := range summoners {
-				err := u.RetryWithBackoff(func() error {
-					return b.checkSummonerUpdates(summoner)
-				}, u.DefaultRetryConfig)
-				if err != nil {
-					if _, nonRetryable := err.(*u.NonRetryableError); nonRetryable {
-						log.Printf("Non-retryable error occurred while checking matches for %s: %v", summoner.Summoner.Name, err)
-					} else {
-						log.Printf("Failed to check matches for %s after multiple retries: %v", summoner.Summoner.Name, err)
-					}
-				}
-			}
+			b.trackMatchesPass(summoners)
 		}
 	}
 }
 
-func (b *Bot) checkSummonerUpdates(summoner s.SummonerWithGuilds) error {
-	summonerUUID, err := b.storage.GetSummonerUUIDFromRiotID(summoner.Summoner.RiotSummonerID)
+// logPausedRegions logs the platforms whose match history is down, with the number of summoners due there that
+// are left for later.
+func logPausedRegions(paused map[string]int) {
+	regions := make([]string, 0, len(paused))
+	for region := range paused {
+		regions = append(regions, region)
+	}
+	slices.Sort(regions)
+
+	for _, region := range regions {
+		log.Printf("Match history is down on %s, %d due summoner(s) skipped until it is back", region, paused[region])
+	}
+}
+
+// trackSummonerMatches checks a summoner for new matches, retrying with backoff, and returns the error that
+// made it give up. A panic is recovered and returned as an error, so it doesn't stop the other summoners.
+func (b *Bot) trackSummonerMatches(summoner s.SummonerWithGuilds) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(b.ctx, summonerCheckTimeout)
+	defer cancel()
 
+	err = u.RetryWithBackoff(ctx, func() error {
+		return b.checkSummonerUpdates(ctx, summoner)
+	}, u.DefaultRetryConfig)
 	if err != nil {
-		return u.NewNonRetryableError(fmt.Errorf("error getting internal summonerUUID for %s: %w", summoner.Summoner.Name, err))
+		var nonRetryable *u.NonRetryableError
+		if errors.As(err, &nonRetryable) {
+			return fmt.Errorf("non-retryable error: %w", nonRetryable.Err)
+		}
+		return fmt.Errorf("failed after multiple retries: %w", err)
 	}
 
-	latestSummonerInfo, _ := b.riotClient.GetSummonerByPUUID(summoner.Summoner.SummonerPUUID)
+	return nil
+}
 
-	latestAccountInfo, _ := b.riotClient.GetAccountByPUUID(summoner.Summoner.SummonerPUUID)
+// checkSummonerUpdates checks a summoner for new matches and rank changes, then schedules their next check.
+// The revision date of summoner-v4 changes when they finish a game: while it doesn't, match ids aren't fetched and
+// only the rank is compared, dodges and decay don't change it. Their Riot ID is checked when it changes too,
+// and every nameCheckInterval as renaming doesn't change it.
+func (b *Bot) checkSummonerUpdates(ctx context.Context, summoner s.SummonerWithGuilds) error {
+	summonerUUID, err := b.storage.GetSummonerUUIDFromRiotID(ctx, summoner.Summoner.RiotSummonerID)
 
-	fullName := fmt.Sprintf("%s#%s", latestAccountInfo.SummonerName, latestAccountInfo.SummonerTagLine)
+	if err != nil {
+		return u.NewNonRetryableError(fmt.Errorf("error getting internal summonerUUID for %s: %w", summoner.Summoner.Name, err))
+	}
 
-	err = b.storage.CheckAndUpdateSummonerInfo(summonerUUID, fullName, latestSummonerInfo.ProfileIconID)
+	latestSummonerInfo, err := b.riotClient.GetSummonerByPUUID(ctx, summoner.Summoner.Region, summoner.Summoner.SummonerPUUID)
 	if err != nil {
-		log.Printf("Error updating summoner info for %s: %v", summoner.Summoner.Name, err)
+		return classifyRiotError(fmt.Errorf("error fetching summoner info for %s: %w", summoner.Summoner.Name, err))
 	}
 
-	if summoner.Summoner.Name != fullName {
-		summoner.Summoner.Name = fullName
+	revisionChanged := latestSummonerInfo.RevisionDate != summoner.Summoner.RevisionDate
+
+	if revisionChanged || time.Since(summoner.NameCheckedAt) >= nameCheckInterval {
+		latestAccountInfo, err := b.riotClient.GetAccountByPUUID(ctx, summoner.Summoner.Region, summoner.Summoner.SummonerPUUID)
+		if err != nil {
+			return classifyRiotError(fmt.Errorf("error fetching account info for %s: %w", summoner.Summoner.Name, err))
+		}
+
+		fullName := fmt.Sprintf("%s#%s", latestAccountInfo.SummonerName, latestAccountInfo.SummonerTagLine)
+
+		previousName, err := b.storage.CheckAndUpdateSummonerInfo(ctx, summonerUUID, fullName, latestSummonerInfo.ProfileIconID)
+		if err != nil {
+			log.Printf("Error updating summoner info for %s: %v", summoner.Summoner.Name, err)
+		}
+
+		if summoner.Summoner.Name != fullName {
+			summoner.Summoner.Name = fullName
+		}
+		summoner.Summoner.ProfileIconID = latestSummonerInfo.ProfileIconID
+
+		if previousName != "" {
+			b.announceNameChange(summoner, previousName)
+		}
 	}
 
-	previousRank, err := b.storage.GetPreviousRank(summonerUUID)
+	leagueEntries, err := b.riotClient.GetLeagueEntries(ctx, summoner.Summoner.Region, summoner.Summoner.SummonerPUUID)
 	if err != nil {
-		return u.NewNonRetryableError(fmt.Errorf("error getting previous rank: %w", err))
+		return classifyRiotError(fmt.Errorf("error fetching current rank for %s: %w", summoner.Summoner.Name, err))
 	}
 
-	currentRankInfo, err := b.riotClient.GetSummonerRank(summoner.Summoner.RiotSummonerID)
+	waitForMatches := time.Since(time.UnixMilli(latestSummonerInfo.RevisionDate)) < matchIndexGracePeriod
+
+	played, pending := false, false
+	for _, queueID := range summoner.TrackedQueues() {
+		// only the guilds tracking this queue for the summoner are told about it
+		queueSummoner := summoner
+		queueSummoner.GuildIDs = summoner.GuildsTrackingQueue(queueID)
+
+		newMatches, more, err := b.checkQueueUpdates(ctx, queueSummoner, queueID, leagueEntries, summonerUUID, revisionChanged, waitForMatches)
+		if err != nil {
+			// the other queues are still checked, this one is looked at again at the next check
+			log.Printf("Error checking %s of %s: %v", riotapi.QueueName(queueID), summoner.Summoner.Name, err)
+			pending = true
+			continue
+		}
+		played = played || newMatches > 0
+		pending = pending || more
+	}
+
+	if err := b.checkMasteryUpdates(ctx, summoner, summonerUUID, played); err != nil {
+		log.Printf("Error checking champion masteries for %s: %v", summoner.Summoner.Name, err)
+	}
+
+	now := time.Now()
+	lastActiveAt := summoner.LastActiveAt
+	if revisionChanged || played || lastActiveAt.IsZero() {
+		lastActiveAt = now
+	}
+
+	// matches left for the next check, and queues that failed, are only looked at again while the revision date
+	// differs from the stored one
+	revisionDate := latestSummonerInfo.RevisionDate
+	if pending {
+		revisionDate = summoner.Summoner.RevisionDate
+	}
+
+	if !b.isLeader() {
+		return u.NewNonRetryableError(errNotLeader)
+	}
+
+	nextCheckAt := now.Add(pollInterval(now.Sub(lastActiveAt)))
+	if err := b.storage.UpdateSummonerSchedule(ctx, summonerUUID, revisionDate, lastActiveAt, nextCheckAt); err != nil {
+		log.Printf("Error scheduling next check of %s: %v", summoner.Summoner.Name, err)
+	}
+
+	return nil
+}
+
+// checkQueueUpdates announces the new matches a summoner played in a queue and, for ranked queues,
+// the rank changes that happened without any match (dodges, decay...).
+// New matches are looked for when fetchMatches is set, or when league-v4 counts ranked games that aren't stored.
+// While waitForMatches is set, games counted by league-v4 but not listed by match-v5 yet are left for the next check
+// instead of taking their LP for a rank change without match.
+// It returns the number of new matches found, and whether more are left for the next check.
+func (b *Bot) checkQueueUpdates(ctx context.Context, summoner s.SummonerWithGuilds, queueID int, leagueEntries []riotapi.LeagueEntry, summonerUUID uuid.UUID, fetchMatches, waitForMatches bool) (int, bool, error) {
+	queueType := riotapi.LeagueQueueType(queueID)
+
+	var previousRank *s.PreviousRank
+	var currentRankInfo *riotapi.LeagueEntry
+	if queueType != "" {
+		var err error
+		previousRank, err = b.storage.GetPreviousRank(ctx, summonerUUID, queueType)
+		if err != nil {
+			return 0, false, u.NewNonRetryableError(fmt.Errorf("error getting previous rank: %w", err))
+		}
+
+		currentRankInfo = riotapi.FindLeagueEntry(leagueEntries, queueType)
+		fetchMatches = fetchMatches || unlistedGames(previousRank, currentRankInfo, nil) > 0
+	}
+
+	var newMatches []*riotapi.MatchData
+	var more bool
+	if fetchMatches {
+		matches, moreMatches, err := b.checkForNewMatches(ctx, summoner.Summoner, queueID)
+		if err != nil {
+			return 0, false, classifyRiotError(err)
+		}
+		newMatches, more = matches, moreMatches
+	}
+
+	if riotapi.HasLanes(queueID) {
+		for _, match := range newMatches {
+			b.addLaningStats(ctx, summoner.Summoner, match)
+		}
+	}
+
+	if queueType == "" {
+		for _, match := range newMatches {
+			b.processUnrankedMatch(ctx, summoner, match, summonerUUID)
+		}
+		return len(newMatches), more, nil
+	}
+
+	// the new leader checks the summoner again, nothing is stored or announced by this instance anymore
+	if !b.isLeader() {
+		return 0, false, u.NewNonRetryableError(errNotLeader)
+	}
+
+	// the current rank already includes games match-v5 doesn't list yet, it is left for the next check
+	if waitForMatches && unlistedGames(previousRank, currentRankInfo, newMatches) > 0 {
+		log.Printf("Waiting for match-v5 to list the last %s games of %s", queueType, summoner.Summoner.Name)
+		more = true
+	}
+
+	switch {
+	case len(newMatches) > 0:
+		b.processNewMatches(ctx, summoner, newMatches, previousRank, currentRankInfo, summonerUUID, more)
+	case previousRank == nil, isSplitResetPending(previousRank, currentRankInfo):
+		// first time this queue is seen for the summoner, or since the split rollover,
+		// there is nothing to compare the rank with yet
+		if err := b.storage.UpdateLeagueEntry(ctx, summonerUUID, currentRankInfo); err != nil {
+			log.Printf("Error storing %s rank for %s: %v", queueType, summoner.Summoner.Name, err)
+		}
+		// until Riot resets ranks, the rank shown is the one of the split that ended
+		if previousRank == nil {
+			b.recordSeasonPeak(ctx, summoner.Summoner, summonerUUID, currentRankInfo)
+		}
+	case more:
+		// the rank changed with a game not listed yet, not without match
+	case hasRankChanged(previousRank, currentRankInfo):
+		b.processRankChange(ctx, summoner, previousRank, currentRankInfo, summonerUUID)
+	case unlistedGames(previousRank, currentRankInfo, nil) > 0:
+		// match-v5 never listed them in time, the games are counted from now on so they aren't looked for again
+		if err := b.storage.UpdateLeagueEntry(ctx, summonerUUID, currentRankInfo); err != nil {
+			log.Printf("Error storing %s rank for %s: %v", queueType, summoner.Summoner.Name, err)
+		}
+	}
+
+	if previousRank != nil {
+		b.checkApexCutoffs(summoner, previousRank, currentRankInfo)
+	}
+
+	return len(newMatches), more, nil
+}
+
+// addLaningStats fetches the timeline of a match and attaches the laning stats of the summoner to it.
+// The match is still stored and announced without them when the timeline can't be fetched.
+func (b *Bot) addLaningStats(ctx context.Context, summoner riotapi.Summoner, match *riotapi.MatchData) {
+	if isRemake(match) {
+		return
+	}
+
+	timeline, err := b.riotClient.GetMatchTimeline(ctx, match.MatchID)
 	if err != nil {
-		return fmt.Errorf("error fetching current rank for %s: %w", summoner.Summoner.Name, err)
+		log.Printf("Error fetching timeline of %s for %s: %v", match.MatchID, summoner.Name, err)
+		return
 	}
 
-	matchFound, newMatch, err := b.checkForNewMatch(summoner.Summoner)
+	laning, err := timeline.LaningStats(summoner.SummonerPUUID, match.Participants)
 	if err != nil {
-		if riotapi.IsRateLimitError(err) {
-			return err // Retryable error
-		}
+		log.Printf("Error computing laning stats of %s for %s: %v", match.MatchID, summoner.Name, err)
+		return
+	}
+
+	match.Laning = laning
+}
+
+// classifyRiotError wraps every error Riot answered with in a NonRetryableError, so RetryWithBackoff gives up on
+// them right away: rate limits and outages were already retried by the Riot client, up to its own cap, and the
+// other ones (404 not found, 401/403 bad key...) won't succeed on a later attempt.
+// Network errors are returned as is, to be retried.
+func classifyRiotError(err error) error {
+	var apiErr *riotapi.RiotAPIError
+	if errors.As(err, &apiErr) || !riotapi.IsRetryableError(err) {
 		return u.NewNonRetryableError(err)
 	}
 
-	if matchFound {
-		b.processNewMatch(summoner, newMatch, previousRank, currentRankInfo, summonerUUID)
-	} else if hasRankChanged(previousRank, currentRankInfo) {
-		b.processRankChange(summoner, previousRank, currentRankInfo, summonerUUID)
+	return err
+}
+
+// isSplitResetPending reports whether a summoner is still ranked while their stored rank was reset at a split
+// rollover, which happens when Riot resets ranks after the start date of the calendar. The rank is stored
+// back without announcement until Riot resets it. After new games, the summoner may also have completed their
+// placements, see processNewMatches.
+func isSplitResetPending(prev *s.PreviousRank, current *riotapi.LeagueEntry) bool {
+	return prev.PrevTier == "UNRANKED" && prev.PrevRank == "" && current.Tier != "UNRANKED"
+}
+
+// unlistedGames returns how many ranked games league-v4 counted since the stored rank that aren't among matches,
+// 0 when the number of games of the stored rank is unknown. Remakes aren't counted by league-v4.
+func unlistedGames(prev *s.PreviousRank, current *riotapi.LeagueEntry, matches []*riotapi.MatchData) int {
+	if prev == nil || prev.PrevGames == 0 {
+		return 0
 	}
 
-	return nil
+	unlisted := current.Wins + current.Losses - prev.PrevGames
+	for _, match := range matches {
+		if !isRemake(match) {
+			unlisted--
+		}
+	}
+
+	return max(unlisted, 0)
 }
 
 func hasRankChanged(prev *s.PreviousRank, current *riotapi.LeagueEntry) bool {
@@ -114,203 +372,259 @@ func hasRankChanged(prev *s.PreviousRank, current *riotapi.LeagueEntry) bool {
 		prev.PrevLP != current.LeaguePoints
 }
 
-func (b *Bot) processRankChange(summoner s.SummonerWithGuilds, prev *s.PreviousRank, current *riotapi.LeagueEntry, summonerUUID uuid.UUID) {
-	lpChange := b.storage.CalculateLPChange(prev.PrevTier, current.Tier, prev.PrevRank, current.Rank, prev.PrevLP, current.LeaguePoints)
-
-	if err := b.storage.UpdateLeagueEntry(summonerUUID, current.LeaguePoints, current.Tier, current.Rank); err != nil {
-		log.Printf("Error updating league entry for %s: %v", summoner.Summoner.Name, err)
+// processNewMatches processes every match a summoner played since the last poll, oldest first.
+// Riot only exposes the current rank, so LP can only be attributed to the last LP-affecting game (remakes don't
+// count): when several games are caught up at once, the earlier ones are announced without LP and the last one
+// carries the combined change. When more matches are left for the next check, the current rank includes them:
+// none of these matches carries LP and the stored rank is left for the last match of the catch-up.
+func (b *Bot) processNewMatches(ctx context.Context, summoner s.SummonerWithGuilds, newMatches []*riotapi.MatchData, previousRank *s.PreviousRank, currentRankInfo *riotapi.LeagueEntry, summonerUUID uuid.UUID, more bool) {
+	lastLPMatch := -1
+	lpGames := 0
+	for idx, match := range newMatches {
+		if !isRemake(match) {
+			lastLPMatch = idx
+			lpGames++
+		}
 	}
 
-	if err := b.storage.CreateNewRowInLPHistory(summonerUUID, "DODGE", lpChange, current.LeaguePoints, current.Tier, current.Rank); err != nil {
-		log.Printf("Error creating new row in lp history for %s: %v", summoner.Summoner.Name, err)
+	resetPending := false
+	if previousRank != nil && isSplitResetPending(previousRank, currentRankInfo) {
+		// Riot resets wins and losses along with ranks: counting more games than the placements played since the
+		// rollover, the rank is still the one of the split that ended and these games aren't placements
+		placements, err := b.storage.GetCurrentPlacementGames(ctx, summonerUUID, currentRankInfo.QueueType)
+		if err != nil {
+			log.Printf("Error getting placement status for %s: %v", summoner.Summoner.Name, err)
+		} else {
+			resetPending = currentRankInfo.Wins+currentRankInfo.Losses > placements.TotalGames+lpGames
+		}
 	}
 
-	embed := b.prepareRankChangeEmbed(summoner.Summoner, prev, current, lpChange)
-
-	for _, guildID := range summoner.GuildIDs {
-		if err := b.announceNewMatch(guildID, embed); err != nil {
-			log.Printf("Error announcing rank change for %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+	if (previousRank == nil && currentRankInfo.Tier != "UNRANKED") || resetPending {
+		// first games seen in a queue the summoner was already placed in, or since the split rollover while Riot
+		// didn't reset ranks yet, the LP they gave can't be known
+		if err := b.storage.UpdateLeagueEntry(ctx, summonerUUID, currentRankInfo); err != nil {
+			log.Printf("Error storing %s rank for %s: %v", currentRankInfo.QueueType, summoner.Summoner.Name, err)
+		}
+		if !resetPending {
+			b.recordSeasonPeak(ctx, summoner.Summoner, summonerUUID, currentRankInfo)
+		}
+		previousRank = &s.PreviousRank{
+			PrevTier: currentRankInfo.Tier,
+			PrevRank: currentRankInfo.Rank,
+			PrevLP:   currentRankInfo.LeaguePoints,
 		}
+		lastLPMatch = len(newMatches)
 	}
 
-	log.Printf("%s probably dodged a game", summoner.Summoner.Name)
-}
-
-func (b *Bot) prepareRankChangeEmbed(summoner riotapi.Summoner, prev *s.PreviousRank, current *riotapi.LeagueEntry, lpChange int) *dg.MessageEmbed {
-	winRate := u.CalculateWinRate(current.Wins, current.Losses)
-	embedColor := 0xFF0000
-
-	currentVersion, _ := b.riotClient.GetCurrentDDragonVersion()
+	if more {
+		lastLPMatch = len(newMatches)
+	}
 
-	profileIconImageURL := fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/profileicon/%d.png", currentVersion, summoner.ProfileIconID)
+	if len(newMatches) > 1 {
+		log.Printf("Catching up on %d matches for %s", len(newMatches), summoner.Summoner.Name)
+	}
 
-	unixTimestamp := time.Now().UnixNano() / int64(time.Millisecond)
-	oldRank := fmt.Sprintf("%s %s (%dlp)", prev.PrevTier, prev.PrevRank, prev.PrevLP)
-	currentRank := fmt.Sprintf("%s %s (%dlp)", current.Tier, current.Rank, current.LeaguePoints)
-	fullFooterStr := fmt.Sprintf("%s -> %s • %s", oldRank, currentRank, u.FormatTime(unixTimestamp))
+	for idx, match := range newMatches {
+		rankKnown := idx >= lastLPMatch
+		b.processNewMatch(ctx, summoner, match, previousRank, currentRankInfo, summonerUUID, rankKnown, lpGames)
 
-	embed := &dg.MessageEmbed{
-		Title:       fmt.Sprintf("%s (%+d LP)", summoner.Name, lpChange),
-		Color:       embedColor,
-		Description: "Probably a dodge 😱",
-		Thumbnail: &dg.MessageEmbedThumbnail{
-			URL: profileIconImageURL,
-		},
-		Fields: []*dg.MessageEmbedField{
-			{
-				Name:   "Wins",
-				Value:  fmt.Sprintf("%d", current.Wins),
-				Inline: true,
-			},
-			{
-				Name:   "Losses",
-				Value:  fmt.Sprintf("%d", current.Losses),
-				Inline: true,
-			},
-			{
-				Name:   "Win Rate",
-				Value:  fmt.Sprintf("%.1f%%", winRate),
-				Inline: true,
-			},
-		},
-		Footer: &dg.MessageEmbedFooter{
-			Text: fullFooterStr,
-		},
+		if idx == lastLPMatch && previousRank != nil {
+			// following remakes are compared against the rank reached after the last LP-affecting game
+			previousRank = &s.PreviousRank{
+				PrevTier: currentRankInfo.Tier,
+				PrevRank: currentRankInfo.Rank,
+				PrevLP:   currentRankInfo.LeaguePoints,
+			}
+		}
 	}
-
-	return embed
 }
 
-// processNewMatch processes a new match
-func (b *Bot) processNewMatch(summoner s.SummonerWithGuilds, newMatch *riotapi.MatchData, previousRank *s.PreviousRank, currentRankInfo *riotapi.LeagueEntry, summonerUUID uuid.UUID) {
-	currentVersion, _ := b.riotClient.GetCurrentDDragonVersion()
+// processNewMatch processes a new match and announces it.
+//   - rankKnown reports whether currentRankInfo reflects the rank right after this match.
+//     When false, the match is stored and announced without LP change.
+//   - lpGames is the number of LP-affecting games covered by the LP change of this poll.
+func (b *Bot) processNewMatch(ctx context.Context, summoner s.SummonerWithGuilds, newMatch *riotapi.MatchData, previousRank *s.PreviousRank, currentRankInfo *riotapi.LeagueEntry, summonerUUID uuid.UUID, rankKnown bool, lpGames int) {
+	queueType := riotapi.LeagueQueueType(newMatch.QueueID)
 
 	wasInPlacements := (previousRank == nil || (previousRank.PrevTier == "UNRANKED" && previousRank.PrevRank == ""))
-	isRemake := newMatch.GameDuration < 210
 
 	if wasInPlacements {
-		if !isRemake {
-			err := b.storage.IncrementPlacementGames(summonerUUID, newMatch.Win)
+		if !isRemake(newMatch) {
+			err := b.storage.IncrementPlacementGames(ctx, summonerUUID, queueType, newMatch.Win)
 			if err != nil {
 				log.Printf("Error incrementing placement games for %s: %v", summoner.Summoner.Name, err)
 				return
 			}
 		}
 
-		err := b.storage.AddPlacementMatch(summonerUUID, newMatch)
+		err := b.storage.AddPlacementMatch(ctx, summonerUUID, newMatch)
 		if err != nil {
 			log.Printf("Error adding placement match for %s: %v", summoner.Summoner.Name, err)
 			return
 		}
 
-		updatedPlacementStatus, err := b.storage.GetCurrentPlacementGames(summonerUUID)
+		updatedPlacementStatus, err := b.storage.GetCurrentPlacementGames(ctx, summonerUUID, queueType)
 		if err != nil {
 			log.Printf("Error getting updated placement status for %s: %v", summoner.Summoner.Name, err)
 			return
 		}
 
 		var embed *dg.MessageEmbed
-		if currentRankInfo.Tier != "UNRANKED" || updatedPlacementStatus.TotalGames == 5 {
-			embed = b.preparePlacementCompletionEmbed(summoner.Summoner, newMatch, currentVersion, updatedPlacementStatus, currentRankInfo)
+		if rankKnown && (currentRankInfo.Tier != "UNRANKED" || updatedPlacementStatus.TotalGames == 5) {
+			embed = b.preparePlacementCompletionEmbed(summoner.Summoner, newMatch, updatedPlacementStatus, currentRankInfo)
+			b.recordSeasonPeak(ctx, summoner.Summoner, summonerUUID, currentRankInfo)
 
-			err = b.storage.UpdateLeagueEntry(summonerUUID, currentRankInfo.LeaguePoints, currentRankInfo.Tier, currentRankInfo.Rank)
+			err = b.storage.UpdateLeagueEntry(ctx, summonerUUID, currentRankInfo)
 			if err != nil {
 				log.Printf("Error updating summoner rank for %s: %v", summoner.Summoner.Name, err)
 			}
 		} else {
-			embed = b.preparePlacementMatchEmbed(summoner.Summoner, newMatch, currentVersion, updatedPlacementStatus)
+			embed = b.preparePlacementMatchEmbed(summoner.Summoner, newMatch, updatedPlacementStatus)
 		}
 
-		for _, guildID := range summoner.GuildIDs {
-			if err := b.announceNewMatch(guildID, embed); err != nil {
-				log.Printf("Error announcing new placement match for %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
-			}
-		}
+		b.announceMatch(ctx, summoner, newMatch, embed)
 
 		return
 	}
 
-	lpChange, err := b.storage.AddMatchAndGetLPChange(summoner.Summoner.RiotSummonerID, newMatch, currentRankInfo.LeaguePoints, currentRankInfo.Rank, currentRankInfo.Tier)
-	if err != nil {
-		log.Printf("Error storing match and calculating LP change for %s: %v", summoner.Summoner.Name, err)
-		return
+	if !rankKnown {
+		if err := b.storage.AddMatch(ctx, summonerUUID, newMatch); err != nil {
+			log.Printf("Error storing match for %s: %v", summoner.Summoner.Name, err)
+			return
+		}
+		lpGames = 0
 	}
 
-	embed := b.prepareMatchEmbed(summoner.Summoner, newMatch, currentRankInfo, lpChange, currentVersion, previousRank)
-
-	for _, guildID := range summoner.GuildIDs {
-		if err := b.announceNewMatch(guildID, embed); err != nil {
-			log.Printf("Error announcing new match for %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+	var lpChange int
+	if rankKnown {
+		var err error
+		lpChange, err = b.storage.AddMatchAndGetLPChange(ctx, summoner.Summoner.RiotSummonerID, newMatch, currentRankInfo)
+		if err != nil {
+			log.Printf("Error storing match and calculating LP change for %s: %v", summoner.Summoner.Name, err)
+			return
 		}
 	}
 
+	embed := b.prepareMatchEmbed(summoner.Summoner, newMatch, currentRankInfo, lpChange, lpGames, previousRank)
+	streakEmbed := b.addStreak(ctx, summoner.Summoner, summonerUUID, newMatch, embed)
+
+	var milestoneEmbed *dg.MessageEmbed
+	if rankKnown {
+		milestoneEmbed = b.checkRankMilestone(ctx, summoner.Summoner, summonerUUID, previousRank, currentRankInfo)
+	}
+
+	b.announceMatch(ctx, summoner, newMatch, embed, milestoneEmbed, streakEmbed)
+
 	log.Printf("New match processed for %s in %d guilds", summoner.Summoner.Name, len(summoner.GuildIDs))
 }
 
-// checkForNewMatch checks if there's a new match for the given summoner.
-func (b *Bot) checkForNewMatch(summoner riotapi.Summoner) (bool, *riotapi.MatchData, error) {
-	storedMatchID, err := b.storage.GetLastMatchID(summoner.SummonerPUUID)
+// processUnrankedMatch stores a match of an unranked queue (normals, ARAM, Arena...) and announces it without LP.
+func (b *Bot) processUnrankedMatch(ctx context.Context, summoner s.SummonerWithGuilds, newMatch *riotapi.MatchData, summonerUUID uuid.UUID) {
+	if err := b.storage.AddMatch(ctx, summonerUUID, newMatch); err != nil {
+		log.Printf("Error storing %s match for %s: %v", riotapi.QueueName(newMatch.QueueID), summoner.Summoner.Name, err)
+		return
+	}
+
+	embed := b.prepareUnrankedMatchEmbed(summoner.Summoner, newMatch)
+	streakEmbed := b.addStreak(ctx, summoner.Summoner, summonerUUID, newMatch, embed)
+
+	b.announceMatch(ctx, summoner, newMatch, embed, streakEmbed)
+
+	log.Printf("New %s match processed for %s in %d guilds", riotapi.QueueName(newMatch.QueueID), summoner.Summoner.Name, len(summoner.GuildIDs))
+}
+
+// checkForNewMatches returns the matches of a queue the given summoner played since the last stored one, oldest first,
+// and whether newer ones are left for the next check.
+func (b *Bot) checkForNewMatches(ctx context.Context, summoner riotapi.Summoner, queueID int) ([]*riotapi.MatchData, bool, error) {
+	storedMatchID, storedGameCreation, err := b.storage.GetLastMatch(ctx, summoner.SummonerPUUID, queueID)
 	if err != nil {
-		return false, nil, fmt.Errorf("error getting stored match ID: %v", err)
+		return nil, false, fmt.Errorf("error getting stored match ID: %v", err)
 	}
 
-	hasNewMatch, newMatch, err := b.riotClient.GetNewMatchForSummoner(summoner.SummonerPUUID, storedMatchID)
+	newMatches, more, err := b.riotClient.GetNewMatchesForSummoner(ctx, summoner.Region, summoner.SummonerPUUID, queueID, storedMatchID, storedGameCreation)
 	if err != nil {
-		return false, nil, fmt.Errorf("error checking for new match: %v", err)
+		return nil, false, fmt.Errorf("error checking for new matches: %w", err)
 	}
 
-	return hasNewMatch, newMatch, nil
+	return newMatches, more, nil
 }
 
 // announceNewMatch sends the embed that was previously processed to the channel that was set for updates
 func (b *Bot) announceNewMatch(guildID string, embed *dg.MessageEmbed) error {
+	_, err := b.sendGuildEmbed(guildID, embed)
+	return err
+}
+
+// sendGuildEmbed sends an embed to the channel that was set for updates in a guild and returns the sent message.
+// Nothing is sent once this instance lost the tracker lease.
+func (b *Bot) sendGuildEmbed(guildID string, embed *dg.MessageEmbed) (*dg.Message, error) {
+	if !b.isLeader() {
+		return nil, errNotLeader
+	}
+
 	channelID, err := b.storage.GetGuildChannelID(guildID)
 	if err != nil {
-		return fmt.Errorf("error getting channel ID for guild %s: %w", guildID, err)
+		return nil, fmt.Errorf("error getting channel ID for guild %s: %w", guildID, err)
 	}
 
-	return u.RetryWithBackoff(func() error {
-		_, err := b.session.ChannelMessageSendEmbed(channelID, embed)
+	var message *dg.Message
+	err = u.RetryWithBackoff(b.ctx, func() error {
+		message, err = b.session.ChannelMessageSendEmbed(channelID, embed)
 		if err != nil {
 			return fmt.Errorf("error sending embed message to channel %s: %w", channelID, err)
 		}
 		return nil
 	}, u.DefaultRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
 }
 
 // prepareMatchEmbed creates and returns a Discord message embed for a match.
-// It takes summoner information, match data, rank info, LP change, current game version,
+// It takes summoner information, match data, rank info, LP change
 // and previous rank as input to generate a detailed embed about the match result.
-func (b *Bot) prepareMatchEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, rankInfo *riotapi.LeagueEntry, lpChange int, currentVersion string, previousRank *s.PreviousRank) *dg.MessageEmbed {
+// lpGames is the number of games the LP change spans, 0 meaning the LP change of this match is unknown.
+func (b *Bot) prepareMatchEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, rankInfo *riotapi.LeagueEntry, lpChange int, lpGames int, previousRank *s.PreviousRank) *dg.MessageEmbed {
 	winRate := u.CalculateWinRate(rankInfo.Wins, rankInfo.Losses)
 
 	var lpChangeStr string
-	if match.GameDuration < 210 {
+	switch {
+	case isRemake(match):
 		lpChangeStr = "Remake"
-	} else {
+	case lpGames == 0:
+		lpChangeStr = "?LP"
+	default:
 		lpChangeStr = fmt.Sprintf("%+dLP", lpChange)
 	}
 
 	embedColor := getEmbedColor(match.Result, match.GameDuration)
 
 	endOfGameStr := u.FormatTime(match.GameEndTimestamp)
-	oldRank := fmt.Sprintf("%s %s (%dlp)", previousRank.PrevTier, previousRank.PrevRank, previousRank.PrevLP)
-	currentRank := fmt.Sprintf("%s %s (%dlp)", rankInfo.Tier, rankInfo.Rank, rankInfo.LeaguePoints)
-	fullFooterStr := fmt.Sprintf("%s -> %s • %s", oldRank, currentRank, endOfGameStr)
+	fullFooterStr := endOfGameStr
+	if lpGames > 0 {
+		oldRank := u.FormatRank(previousRank.PrevTier, previousRank.PrevRank, previousRank.PrevLP)
+		currentRank := b.formatRankWithLadder(summoner, rankInfo)
+		fullFooterStr = fmt.Sprintf("%s -> %s • %s", oldRank, currentRank, endOfGameStr)
+		if lpGames > 1 {
+			fullFooterStr = fmt.Sprintf("%s -> %s over %d games • %s", oldRank, currentRank, lpGames, endOfGameStr)
+		}
+	}
 	TeamDmgOwnPercentage := fmt.Sprintf(" %.0f%% of team's damage", match.TeamDamagePercentage*100)
-	leagueOfGraphURL := fmt.Sprintf("https://www.leagueofgraphs.com/match/euw/%s", strings.TrimPrefix(match.MatchID, "EUW1_"))
-	displayChampionName := u.ChampionNameMapper(match.ChampionName, false)
-	imageChampionName := u.ChampionNameMapper(match.ChampionName, true)
-	championImageURL := fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/champion/%s.png", currentVersion, imageChampionName)
+	leagueOfGraphURL := leagueOfGraphsMatchURL(match.MatchID)
+	champion := b.ddragon.Champion(match.ChampionName)
+	championImageURL := b.ddragon.ChampionImageURL(champion)
 
 	embed := &dg.MessageEmbed{
 		Title:       fmt.Sprintf("**%s (%s)**", summoner.Name, lpChangeStr),
 		URL:         leagueOfGraphURL,
-		Description: fmt.Sprintf("**%d/%d/%d** with **%s** (%d:%02d) • %s and %.0f%%KP", match.Kills, match.Deaths, match.Assists, displayChampionName, match.GameDuration/60, match.GameDuration%60, TeamDmgOwnPercentage, match.KillParticipation*100),
+		Description: fmt.Sprintf("**%d/%d/%d** with **%s** (%d:%02d) • %s and %.0f%%KP", match.Kills, match.Deaths, match.Assists, champion.Name, match.GameDuration/60, match.GameDuration%60, TeamDmgOwnPercentage, match.KillParticipation*100),
 		Color:       embedColor,
-		Thumbnail: &dg.MessageEmbedThumbnail{
-			URL: championImageURL,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
 		},
+		Thumbnail: thumbnail(championImageURL),
 		Fields: []*dg.MessageEmbedField{
 			{
 				Name:   "Wins",
@@ -333,21 +647,70 @@ func (b *Bot) prepareMatchEmbed(summoner riotapi.Summoner, match *riotapi.MatchD
 		},
 	}
 
+	if match.Laning != nil {
+		embed.Fields = append(embed.Fields, b.laningField(match.Laning))
+	}
+
 	return embed
 }
 
+// laningField returns an embed field with the laning stats of a match, e.g.
+// "@10: +350g +8cs +120xp • @15: -200g +2cs -50xp • First blood 🩸 • 4/6 objectives".
+func (b *Bot) laningField(laning *riotapi.LaningStats) *dg.MessageEmbedField {
+	name := "Laning"
+	if laning.OpponentChampion != "" {
+		name = fmt.Sprintf("Laning vs %s", b.ddragon.ChampionName(laning.OpponentChampion))
+	}
+
+	var parts []string
+	if laning.At10 != nil {
+		parts = append(parts, fmt.Sprintf("@10: %s", formatLaningDiff(laning.At10)))
+	}
+	if laning.At15 != nil {
+		parts = append(parts, fmt.Sprintf("@15: %s", formatLaningDiff(laning.At15)))
+	}
+
+	switch {
+	case laning.FirstBloodKill:
+		parts = append(parts, "First blood 🩸")
+	case laning.FirstBloodAssist:
+		parts = append(parts, "First blood assist")
+	case laning.FirstBloodVictim:
+		parts = append(parts, "Gave first blood")
+	}
+
+	if laning.TeamObjectives > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d objectives", laning.ObjectivesParticipated, laning.TeamObjectives))
+	}
+
+	value := strings.Join(parts, " • ")
+	if value == "" {
+		value = "No lane opponent"
+	}
+
+	return &dg.MessageEmbedField{
+		Name:   name,
+		Value:  value,
+		Inline: false,
+	}
+}
+
+// formatLaningDiff returns "+350g +8cs +120xp" for a difference with the lane opponent.
+func formatLaningDiff(diff *riotapi.LaningDiff) string {
+	return fmt.Sprintf("%+dg %+dcs %+dxp", diff.Gold, diff.CS, diff.XP)
+}
+
 // preparePlacementMatchEmbed returns an embed for placement games
-func (b *Bot) preparePlacementMatchEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, currentVersion string, placementStatus *riotapi.PlacementStatus) *dg.MessageEmbed {
+func (b *Bot) preparePlacementMatchEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, placementStatus *riotapi.PlacementStatus) *dg.MessageEmbed {
 	kda := float64(match.Kills+match.Assists) / math.Max(float64(match.Deaths), 1)
 
 	embedColor := getEmbedColor(match.Result, match.GameDuration)
 
 	endOfGameStr := u.FormatTime(match.GameEndTimestamp)
 	TeamDmgOwnPercentage := fmt.Sprintf(" %.0f%% of team's damage", match.TeamDamagePercentage*100)
-	leagueOfGraphURL := fmt.Sprintf("https://www.leagueofgraphs.com/match/euw/%s", strings.TrimPrefix(match.MatchID, "EUW1_"))
-	displayChampionName := u.ChampionNameMapper(match.ChampionName, false)
-	imageChampionName := u.ChampionNameMapper(match.ChampionName, true)
-	championImageURL := fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/champion/%s.png", currentVersion, imageChampionName)
+	leagueOfGraphURL := leagueOfGraphsMatchURL(match.MatchID)
+	champion := b.ddragon.Champion(match.ChampionName)
+	championImageURL := b.ddragon.ChampionImageURL(champion)
 
 	var placementInfo string
 	if match.GameDuration < 210 {
@@ -364,11 +727,12 @@ func (b *Bot) preparePlacementMatchEmbed(summoner riotapi.Summoner, match *riota
 	embed := &dg.MessageEmbed{
 		Title:       title,
 		URL:         leagueOfGraphURL,
-		Description: fmt.Sprintf("**%d/%d/%d** (**%.2f:1** KDA) with **%s** (%d:%02d)", match.Kills, match.Deaths, match.Assists, kda, displayChampionName, match.GameDuration/60, match.GameDuration%60),
+		Description: fmt.Sprintf("**%d/%d/%d** (**%.2f:1** KDA) with **%s** (%d:%02d)", match.Kills, match.Deaths, match.Assists, kda, champion.Name, match.GameDuration/60, match.GameDuration%60),
 		Color:       embedColor,
-		Thumbnail: &dg.MessageEmbedThumbnail{
-			URL: championImageURL,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
 		},
+		Thumbnail: thumbnail(championImageURL),
 		Fields: []*dg.MessageEmbedField{
 			{
 				Value:  fmt.Sprintf("**%d**CS (%dCS/min) • **%s** and **%.0f%%**KP", match.TotalMinionsKilled+match.NeutralMinionsKilled, (match.TotalMinionsKilled+match.NeutralMinionsKilled)/(match.GameDuration/60), TeamDmgOwnPercentage, match.KillParticipation*100),
@@ -392,10 +756,11 @@ func (b *Bot) preparePlacementMatchEmbed(summoner riotapi.Summoner, match *riota
 }
 
 // preparePlacementCompletionEmbed returns an embed message for placement games completion
-func (b *Bot) preparePlacementCompletionEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, currentVersion string, placementStatus *riotapi.PlacementStatus, newRank *riotapi.LeagueEntry) *dg.MessageEmbed {
-	championImageURL := fmt.Sprintf("https://ddragon.leagueoflegends.com/cdn/%s/img/champion/%s.png", currentVersion, match.ChampionName)
+func (b *Bot) preparePlacementCompletionEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, placementStatus *riotapi.PlacementStatus, newRank *riotapi.LeagueEntry) *dg.MessageEmbed {
+	champion := b.ddragon.Champion(match.ChampionName)
+	championImageURL := b.ddragon.ChampionImageURL(champion)
 	embedColor := getEmbedColor(match.Result, match.GameDuration)
-	leagueOfGraphURL := fmt.Sprintf("https://www.leagueofgraphs.com/match/euw/%s", strings.TrimPrefix(match.MatchID, "EUW1_"))
+	leagueOfGraphURL := leagueOfGraphsMatchURL(match.MatchID)
 	kda := float64(match.Kills+match.Assists) / math.Max(float64(match.Deaths), 1)
 	TeamDmgOwnPercentage := fmt.Sprintf("**%.0f%%** of team's damage", match.TeamDamagePercentage*100)
 
@@ -404,11 +769,12 @@ func (b *Bot) preparePlacementCompletionEmbed(summoner riotapi.Summoner, match *
 	embed := &dg.MessageEmbed{
 		Title:       fmt.Sprintf("%s • Placement complete!", summoner.Name),
 		URL:         leagueOfGraphURL,
-		Description: fmt.Sprintf("**%d/%d/%d** (**%.2f:1** KDA) with **%s** (%d:%02d)", match.Kills, match.Deaths, match.Assists, kda, match.ChampionName, match.GameDuration/60, match.GameDuration%60),
+		Description: fmt.Sprintf("**%d/%d/%d** (**%.2f:1** KDA) with **%s** (%d:%02d)", match.Kills, match.Deaths, match.Assists, kda, champion.Name, match.GameDuration/60, match.GameDuration%60),
 		Color:       embedColor,
-		Thumbnail: &dg.MessageEmbedThumbnail{
-			URL: championImageURL,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
 		},
+		Thumbnail: thumbnail(championImageURL),
 		Fields: []*dg.MessageEmbedField{
 			{
 				Value:  fmt.Sprintf("**%d**CS (%dCS/min) • %s and **%.0f%%**KP", match.TotalMinionsKilled+match.NeutralMinionsKilled, (match.TotalMinionsKilled+match.NeutralMinionsKilled)/(match.GameDuration/60), TeamDmgOwnPercentage, match.KillParticipation*100),
@@ -431,6 +797,71 @@ func (b *Bot) preparePlacementCompletionEmbed(summoner riotapi.Summoner, match *
 	return embed
 }
 
+// prepareUnrankedMatchEmbed returns an embed for matches of unranked queues, which have no LP to show
+func (b *Bot) prepareUnrankedMatchEmbed(summoner riotapi.Summoner, match *riotapi.MatchData) *dg.MessageEmbed {
+	kda := float64(match.Kills+match.Assists) / math.Max(float64(match.Deaths), 1)
+
+	result := match.Result
+	if isRemake(match) {
+		result = "Remake"
+	}
+
+	embedColor := getEmbedColor(match.Result, match.GameDuration)
+
+	endOfGameStr := u.FormatTime(match.GameEndTimestamp)
+	TeamDmgOwnPercentage := fmt.Sprintf(" %.0f%% of team's damage", match.TeamDamagePercentage*100)
+	leagueOfGraphURL := leagueOfGraphsMatchURL(match.MatchID)
+	champion := b.ddragon.Champion(match.ChampionName)
+	championImageURL := b.ddragon.ChampionImageURL(champion)
+
+	embed := &dg.MessageEmbed{
+		Title:       fmt.Sprintf("**%s (%s)**", summoner.Name, result),
+		URL:         leagueOfGraphURL,
+		Description: fmt.Sprintf("**%d/%d/%d** (**%.2f:1** KDA) with **%s** (%d:%02d)", match.Kills, match.Deaths, match.Assists, kda, champion.Name, match.GameDuration/60, match.GameDuration%60),
+		Color:       embedColor,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
+		},
+		Thumbnail: thumbnail(championImageURL),
+		Fields: []*dg.MessageEmbedField{
+			{
+				Value:  fmt.Sprintf("**%d** damage inflicted to champions •%s and **%.0f%%**KP", match.TotalDamageDealtToChampions, TeamDmgOwnPercentage, match.KillParticipation*100),
+				Inline: false,
+			},
+		},
+		Footer: &dg.MessageEmbedFooter{
+			Text: endOfGameStr,
+		},
+	}
+
+	return embed
+}
+
+// leagueOfGraphsMatchURL returns the leagueofgraphs.com page of a match, on the region the match was played on.
+func leagueOfGraphsMatchURL(matchID string) string {
+	platform := riotapi.PlatformFromMatchID(matchID)
+	_, gameID, _ := strings.Cut(matchID, "_")
+
+	return fmt.Sprintf("https://www.leagueofgraphs.com/match/%s/%s", riotapi.LeagueOfGraphsRegion(platform), gameID)
+}
+
+// thumbnail returns an embed thumbnail showing the image at url, or nil when the image couldn't be resolved.
+func thumbnail(url string) *dg.MessageEmbedThumbnail {
+	if url == "" {
+		return nil
+	}
+
+	return &dg.MessageEmbedThumbnail{URL: url}
+}
+
+// remakeMaxDuration is the duration in seconds under which a match is a remake.
+const remakeMaxDuration = 210
+
+// isRemake reports whether a match ended early enough to be a remake (https://leagueoflegends.fandom.com/wiki/Surrendering)
+func isRemake(match *riotapi.MatchData) bool {
+	return match.GameDuration < remakeMaxDuration
+}
+
 // getEmbedColor returns an appropriate color code (in hexadecimal format) based on the match result and game duration.
 //   - If the game duration is less than 210 seconds, it returns grey (0x808080), indicating the match was a remake. (https://leagueoflegends.fandom.com/wiki/Surrendering)
 //   - If the match result is "win" (case-insensitive), it returns green (0x00FF00), indicating a win.
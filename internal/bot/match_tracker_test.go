@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/tristan-derez/league-tracker/internal/ddragon"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+// replayFixturesDir holds the Riot API responses recorded for puuid-blue-middle, who played EUW1_7000000001 and
+// EUW1_7000000002 in ranked solo/duo after EUW1_7000000000.
+const replayFixturesDir = "../riot-api/testdata/riot-fixtures"
+
+// newReplayBot returns a bot whose Riot client is served by the recorded fixtures, without Discord, database
+// nor Data Dragon data.
+func newReplayBot(t *testing.T) *Bot {
+	t.Helper()
+
+	return &Bot{
+		riotClient: riotapi.NewClient("RGAPI-test", "euw1", riotapi.WithTransport(&riotapi.ReplayTransport{Dir: replayFixturesDir})),
+		ddragon:    ddragon.New(t.TempDir()),
+		apexLadder: newApexLadder("euw1"),
+	}
+}
+
+// TestReplayMatchEmbed replays the check of a summoner who played two matches since the last stored one, down to
+// the announcement of the first one with its laning stats.
+func TestReplayMatchEmbed(t *testing.T) {
+	b := newReplayBot(t)
+	ctx := context.Background()
+	summoner := riotapi.Summoner{Name: "BlueMiddle#EUW", SummonerPUUID: "puuid-blue-middle", Region: "euw1"}
+
+	matches, more, err := b.riotClient.GetNewMatchesForSummoner(ctx, summoner.Region, summoner.SummonerPUUID, riotapi.QueueRankedSolo, "EUW1_7000000000", 1760595000000)
+	if err != nil {
+		t.Fatalf("GetNewMatchesForSummoner: %v", err)
+	}
+	if len(matches) != 2 || more {
+		t.Fatalf("GetNewMatchesForSummoner = %d matches, more = %t, want 2 and no more", len(matches), more)
+	}
+
+	match := matches[0]
+	b.addLaningStats(ctx, summoner, match)
+	if match.Laning == nil {
+		t.Fatal("addLaningStats didn't attach laning stats")
+	}
+
+	previousRank := &s.PreviousRank{PrevTier: "EMERALD", PrevRank: "II", PrevLP: 33}
+	current := &riotapi.LeagueEntry{QueueType: riotapi.QueueTypeRankedSolo, Tier: "EMERALD", Rank: "II", LeaguePoints: 54, Wins: 48, Losses: 41}
+
+	embed := b.prepareMatchEmbed(summoner, match, current, 21, 1, previousRank)
+
+	want := &dg.MessageEmbed{
+		Title:       "**BlueMiddle#EUW (+21LP)**",
+		URL:         leagueOfGraphsMatchURL("EUW1_7000000001"),
+		Description: "**7/1/6** with **Ahri** (21:00) •  25% of team's damage and 65%KP",
+		Color:       0x00FF00,
+		Footer:      &dg.MessageEmbedFooter{Text: "EMERALD II (33lp) -> EMERALD II (54lp) • " + u.FormatTime(match.GameEndTimestamp)},
+	}
+	if embed.Title != want.Title || embed.URL != want.URL || embed.Description != want.Description || embed.Color != want.Color || embed.Footer.Text != want.Footer.Text {
+		t.Errorf("prepareMatchEmbed() = %q %q %q %#x %q, want %q %q %q %#x %q",
+			embed.Title, embed.URL, embed.Description, embed.Color, embed.Footer.Text,
+			want.Title, want.URL, want.Description, want.Color, want.Footer.Text)
+	}
+
+	fields := map[string]string{
+		"Wins":             "48",
+		"Losses":           "41",
+		"Win Rate":         "53.9%",
+		"Laning vs Syndra": "@10: +500g +10cs +300xp • @15: +750g +15cs +450xp • Gave first blood • 2/4 objectives",
+	}
+	if len(embed.Fields) != len(fields) {
+		t.Fatalf("prepareMatchEmbed() has %d fields, want %d", len(embed.Fields), len(fields))
+	}
+	for _, field := range embed.Fields {
+		if want, ok := fields[field.Name]; !ok || field.Value != want {
+			t.Errorf("field %q = %q, want %q", field.Name, field.Value, want)
+		}
+	}
+}
+
+func TestUnlistedGames(t *testing.T) {
+	win := &riotapi.MatchData{GameDuration: 1800, Win: true}
+	remake := &riotapi.MatchData{GameDuration: 180}
+
+	tests := []struct {
+		name    string
+		prev    *s.PreviousRank
+		current riotapi.LeagueEntry
+		matches []*riotapi.MatchData
+		want    int
+	}{
+		{
+			name:    "first check of the queue",
+			current: riotapi.LeagueEntry{Wins: 10, Losses: 8},
+			want:    0,
+		},
+		{
+			name:    "games of the stored rank unknown",
+			prev:    &s.PreviousRank{PrevTier: "GOLD", PrevRank: "II"},
+			current: riotapi.LeagueEntry{Wins: 10, Losses: 8},
+			want:    0,
+		},
+		{
+			name:    "no new game",
+			prev:    &s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevGames: 18},
+			current: riotapi.LeagueEntry{Wins: 10, Losses: 8},
+			want:    0,
+		},
+		{
+			name:    "two games not listed yet",
+			prev:    &s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevGames: 16},
+			current: riotapi.LeagueEntry{Wins: 10, Losses: 8},
+			want:    2,
+		},
+		{
+			name:    "one of them listed, remakes aren't counted by league-v4",
+			prev:    &s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevGames: 16},
+			current: riotapi.LeagueEntry{Wins: 10, Losses: 8},
+			matches: []*riotapi.MatchData{remake, win},
+			want:    1,
+		},
+		{
+			name:    "every game listed",
+			prev:    &s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevGames: 17},
+			current: riotapi.LeagueEntry{Wins: 10, Losses: 8},
+			matches: []*riotapi.MatchData{win, win},
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unlistedGames(tt.prev, &tt.current, tt.matches); got != tt.want {
+				t.Errorf("unlistedGames() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSplitResetPending(t *testing.T) {
+	reset := &s.PreviousRank{PrevTier: "UNRANKED"}
+
+	tests := []struct {
+		name    string
+		prev    *s.PreviousRank
+		current riotapi.LeagueEntry
+		want    bool
+	}{
+		{name: "Riot didn't reset ranks yet", prev: reset, current: riotapi.LeagueEntry{Tier: "GOLD", Rank: "I"}, want: true},
+		{name: "Riot reset ranks", prev: reset, current: riotapi.LeagueEntry{Tier: "UNRANKED"}, want: false},
+		{name: "ranked", prev: &s.PreviousRank{PrevTier: "GOLD", PrevRank: "I"}, current: riotapi.LeagueEntry{Tier: "GOLD", Rank: "I"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSplitResetPending(tt.prev, &tt.current); got != tt.want {
+				t.Errorf("isSplitResetPending() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
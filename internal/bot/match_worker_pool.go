@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+)
+
+const (
+	// requestsPerSummonerCheck is about how many Riot requests checking a summoner without new match makes
+	// (summoner, account, league entries and match ids of the tracked queues).
+	requestsPerSummonerCheck = 5
+	// riotRequestLatency is the typical round trip of a Riot request. Workers beyond the requests the budget
+	// lets run during one round trip would only wait for the rate limiter.
+	riotRequestLatency = 500 * time.Millisecond
+	// minMatchWorkers keeps a summoner waiting between retries from holding back the others, even on a small budget.
+	minMatchWorkers = 2
+	// maxMatchWorkers caps the concurrent checks of a production key, the database and Discord are shared too.
+	maxMatchWorkers = 16
+	// matchAnnouncementTTL is how long the announcement of a match is remembered, a match is only found again
+	// by a check running when the tracker lease moves.
+	matchAnnouncementTTL = 7 * 24 * time.Hour
+)
+
+// matchWorkerCount returns the number of summoners to check concurrently for a budget in requests per second.
+func matchWorkerCount(requestsPerSecond float64) int {
+	workers := int(math.Ceil(requestsPerSecond * riotRequestLatency.Seconds()))
+
+	return min(max(workers, minMatchWorkers), maxMatchWorkers)
+}
+
+// trackMatchesPass checks every summoner for new matches with a pool of workers sized on the Riot request budget.
+// A summoner failing doesn't affect the others, the failures are logged as they happen and summed up at the end.
+// The pass stops handing out summoners when the bot context is cancelled, the Riot API key becomes invalid or this
+// instance loses the leadership.
+func (b *Bot) trackMatchesPass(summoners []s.SummonerWithGuilds) {
+	start := time.Now()
+
+	budget := b.riotClient.RequestsPerSecond(b.config.RiotAPIRegion)
+	workers := min(matchWorkerCount(budget), len(summoners))
+	estimate := time.Duration(float64(len(summoners)*requestsPerSummonerCheck) / budget * float64(time.Second))
+
+	log.Printf("🕵️ Tracking matches for %d summoners with %d workers (%.1f requests/s, about %s)", len(summoners), workers, budget, estimate.Round(time.Second))
+	if estimate > trackMatchesInterval {
+		log.Printf("Warning: checking %d summoners takes longer than the %s tracking interval with the current Riot API key", len(summoners), trackMatchesInterval)
+	}
+
+	if err := b.storage.DeleteStaleMatchAnnouncements(b.ctx, time.Now().Add(-matchAnnouncementTTL)); err != nil {
+		log.Printf("Error deleting stale match announcements: %v", err)
+	}
+
+	jobs := make(chan s.SummonerWithGuilds)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		checked int
+		failed  []string
+	)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for summoner := range jobs {
+				err := b.trackSummonerMatches(summoner)
+
+				mu.Lock()
+				checked++
+				if err != nil {
+					failed = append(failed, summoner.Summoner.Name)
+				}
+				mu.Unlock()
+
+				if err != nil {
+					log.Printf("Error checking matches for %s: %v", summoner.Summoner.Name, err)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, summoner := range summoners {
+		if b.riotClient.KeyInvalid() || !b.isLeader() {
+			break
+		}
+
+		select {
+		case <-b.ctx.Done():
+			break feed
+		case jobs <- summoner:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(failed) > 0 {
+		log.Printf("Match tracking pass done in %s: %d/%d summoners checked, %d failed (%s)", time.Since(start).Round(time.Second), checked, len(summoners), len(failed), strings.Join(failed, ", "))
+		return
+	}
+
+	log.Printf("Match tracking pass done in %s: %d/%d summoners checked", time.Since(start).Round(time.Second), checked, len(summoners))
+}
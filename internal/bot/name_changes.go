@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	dg "github.com/bwmarrin/discordgo"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+)
+
+// announceNameChange posts in the guilds tracking a summoner that they changed their Riot ID.
+// summoner already carries the new name.
+func (b *Bot) announceNameChange(summoner s.SummonerWithGuilds, previousName string) {
+	embed := &dg.MessageEmbed{
+		Title:       "📝 Riot ID change",
+		Color:       0x7289DA,
+		Description: fmt.Sprintf("**%s** is now **%s**", previousName, summoner.Summoner.Name),
+		Thumbnail:   thumbnail(b.ddragon.ProfileIconURL(summoner.Summoner.ProfileIconID)),
+		Footer: &dg.MessageEmbedFooter{
+			Text: "Commands still accept the former Riot ID",
+		},
+	}
+
+	for _, guildID := range summoner.GuildIDs {
+		if err := b.announceNewMatch(guildID, embed); err != nil {
+			log.Printf("Error announcing Riot ID change of %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+		}
+	}
+
+	log.Printf("%s changed their Riot ID to %s", previousName, summoner.Summoner.Name)
+}
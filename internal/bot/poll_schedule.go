@@ -0,0 +1,33 @@
+package bot
+
+import "time"
+
+// pollSchedule maps how long a summoner has been idle to how often they are checked for new matches,
+// from the most to the least recently active. Summoners idle for longer than every entry use dormantPollInterval.
+var pollSchedule = []struct {
+	idle     time.Duration
+	interval time.Duration
+}{
+	{idle: 24 * time.Hour, interval: trackMatchesInterval},
+	{idle: 7 * 24 * time.Hour, interval: 20 * time.Minute},
+	{idle: 30 * 24 * time.Hour, interval: time.Hour},
+}
+
+// dormantPollInterval is how often summoners who haven't played for a month are checked.
+const dormantPollInterval = 24 * time.Hour
+
+// nameCheckInterval is how often the Riot ID of a summoner is checked when they haven't played,
+// a rename doesn't change their revision date.
+const nameCheckInterval = 6 * time.Hour
+
+// pollInterval returns how long to wait before checking again a summoner last active idle ago.
+// A summoner found in game by TrackLiveGames becomes due right away, see Storage.MarkSummonersActive.
+func pollInterval(idle time.Duration) time.Duration {
+	for _, step := range pollSchedule {
+		if idle < step.idle {
+			return step.interval
+		}
+	}
+
+	return dormantPollInterval
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+// dodgeLPPenalties are the LP lost for the first and the following dodges of a day below Master.
+var dodgeLPPenalties = []int{-5, -15}
+
+// rankEventStyles are the description and color of the announcement of each rank event type.
+var rankEventStyles = map[s.RankEventType]struct {
+	description string
+	color       int
+}{
+	s.RankEventDodge:       {description: "Dodged a game 😱", color: 0xFF0000},
+	s.RankEventDecay:       {description: "Decayed from inactivity 💤", color: 0x95A5A6},
+	s.RankEventLadder:      {description: "Moved by the daily ladder update 🪜", color: 0x9B59B6},
+	s.RankEventSeasonReset: {description: "Rank reset for the new season 🌱", color: 0x3498DB},
+	s.RankEventCorrection:  {description: "LP adjusted by Riot 🛠️", color: 0xF1C40F},
+}
+
+// classifyRankEvent returns what most likely caused a rank change that happened without any match.
+func classifyRankEvent(prev *s.PreviousRank, current *riotapi.LeagueEntry, lpChange int) s.RankEventType {
+	prevApex := u.IsApexTier(prev.PrevTier)
+
+	switch {
+	case strings.EqualFold(current.Tier, "UNRANKED"):
+		return s.RankEventSeasonReset
+	case prevApex && u.IsApexTier(current.Tier) && lpChange == 0:
+		// Grandmaster and Challenger are given to the best players of the ladder, without any LP change
+		return s.RankEventLadder
+	case prevApex && lpChange < 0:
+		// decay removes LP every day of inactivity, down to Diamond I
+		return s.RankEventDecay
+	case !prevApex && isDodgePenalty(prev, current, lpChange):
+		return s.RankEventDodge
+	default:
+		return s.RankEventCorrection
+	}
+}
+
+// isDodgePenalty reports whether an LP loss matches a dodge penalty. Dodges don't demote: at the bottom of a
+// division the loss is capped by the LP the summoner had.
+func isDodgePenalty(prev *s.PreviousRank, current *riotapi.LeagueEntry, lpChange int) bool {
+	if !strings.EqualFold(prev.PrevTier, current.Tier) || prev.PrevRank != current.Rank {
+		return false
+	}
+
+	for _, penalty := range dodgeLPPenalties {
+		if lpChange == penalty || (current.LeaguePoints == 0 && lpChange < 0 && lpChange > penalty) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// processRankChange records and announces a rank change that happened without any match (dodge, decay...).
+func (b *Bot) processRankChange(ctx context.Context, summoner s.SummonerWithGuilds, prev *s.PreviousRank, current *riotapi.LeagueEntry, summonerUUID uuid.UUID) {
+	lpChange := b.storage.CalculateLPChange(prev.PrevTier, current.Tier, prev.PrevRank, current.Rank, prev.PrevLP, current.LeaguePoints)
+
+	event := s.RankEvent{
+		Type:      classifyRankEvent(prev, current, lpChange),
+		QueueType: current.QueueType,
+		LPChange:  lpChange,
+		Previous:  *prev,
+		NewTier:   current.Tier,
+		NewRank:   current.Rank,
+		NewLP:     current.LeaguePoints,
+	}
+
+	if err := b.storage.UpdateLeagueEntry(ctx, summonerUUID, current); err != nil {
+		log.Printf("Error updating league entry for %s: %v", summoner.Summoner.Name, err)
+	}
+
+	if err := b.storage.AddRankEvent(ctx, summonerUUID, event); err != nil {
+		log.Printf("Error storing rank event for %s: %v", summoner.Summoner.Name, err)
+	}
+
+	// a ladder update can promote to Grandmaster or Challenger
+	b.recordSeasonPeak(ctx, summoner.Summoner, summonerUUID, current)
+
+	embed := b.prepareRankEventEmbed(summoner.Summoner, event, current)
+
+	for _, guildID := range summoner.GuildIDs {
+		if err := b.announceNewMatch(guildID, embed); err != nil {
+			log.Printf("Error announcing rank change for %s in guild %s: %v", summoner.Summoner.Name, guildID, err)
+		}
+	}
+
+	log.Printf("%s rank changed without a match (%s, %+d LP)", summoner.Summoner.Name, event.Type, lpChange)
+}
+
+// prepareRankEventEmbed returns an embed announcing a rank change that happened without any match,
+// worded and colored after its type.
+func (b *Bot) prepareRankEventEmbed(summoner riotapi.Summoner, event s.RankEvent, current *riotapi.LeagueEntry) *dg.MessageEmbed {
+	style := rankEventStyles[event.Type]
+	winRate := u.CalculateWinRate(current.Wins, current.Losses)
+
+	profileIconImageURL := b.ddragon.ProfileIconURL(summoner.ProfileIconID)
+
+	unixTimestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	oldRank := u.FormatRank(event.Previous.PrevTier, event.Previous.PrevRank, event.Previous.PrevLP)
+	currentRank := b.formatRankWithLadder(summoner, current)
+	fullFooterStr := fmt.Sprintf("%s -> %s • %s", oldRank, currentRank, u.FormatTime(unixTimestamp))
+
+	// the LP of a reset rank has no meaning
+	title := fmt.Sprintf("%s (%+d LP)", summoner.Name, event.LPChange)
+	if event.Type == s.RankEventSeasonReset || event.Type == s.RankEventLadder {
+		title = summoner.Name
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       title,
+		Color:       style.color,
+		Description: style.description,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueTypeName(current.QueueType),
+		},
+		Thumbnail: thumbnail(profileIconImageURL),
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:   "Wins",
+				Value:  fmt.Sprintf("%d", current.Wins),
+				Inline: true,
+			},
+			{
+				Name:   "Losses",
+				Value:  fmt.Sprintf("%d", current.Losses),
+				Inline: true,
+			},
+			{
+				Name:   "Win Rate",
+				Value:  fmt.Sprintf("%.1f%%", winRate),
+				Inline: true,
+			},
+		},
+		Footer: &dg.MessageEmbedFooter{
+			Text: fullFooterStr,
+		},
+	}
+
+	return embed
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"testing"
+
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+)
+
+func TestClassifyRankEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     s.PreviousRank
+		current  riotapi.LeagueEntry
+		lpChange int
+		want     s.RankEventType
+	}{
+		{
+			name:     "first dodge of the day",
+			prev:     s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 40},
+			current:  riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 35},
+			lpChange: -5,
+			want:     s.RankEventDodge,
+		},
+		{
+			name:     "second dodge of the day",
+			prev:     s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 35},
+			current:  riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 20},
+			lpChange: -15,
+			want:     s.RankEventDodge,
+		},
+		{
+			name:     "dodge capped at 0 LP",
+			prev:     s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 3},
+			current:  riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 0},
+			lpChange: -3,
+			want:     s.RankEventDodge,
+		},
+		{
+			name:     "LP loss that isn't a dodge penalty",
+			prev:     s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 40},
+			current:  riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 32},
+			lpChange: -8,
+			want:     s.RankEventCorrection,
+		},
+		{
+			name:     "demotion without match",
+			prev:     s.PreviousRank{PrevTier: "GOLD", PrevRank: "IV", PrevLP: 0},
+			current:  riotapi.LeagueEntry{Tier: "SILVER", Rank: "I", LeaguePoints: 75},
+			lpChange: -25,
+			want:     s.RankEventCorrection,
+		},
+		{
+			name:     "decay in Master",
+			prev:     s.PreviousRank{PrevTier: "MASTER", PrevRank: "I", PrevLP: 120},
+			current:  riotapi.LeagueEntry{Tier: "MASTER", Rank: "I", LeaguePoints: 45},
+			lpChange: -75,
+			want:     s.RankEventDecay,
+		},
+		{
+			name:     "decay out of Master",
+			prev:     s.PreviousRank{PrevTier: "MASTER", PrevRank: "I", PrevLP: 10},
+			current:  riotapi.LeagueEntry{Tier: "DIAMOND", Rank: "I", LeaguePoints: 75},
+			lpChange: -35,
+			want:     s.RankEventDecay,
+		},
+		{
+			name:     "promoted to Grandmaster by the ladder update",
+			prev:     s.PreviousRank{PrevTier: "MASTER", PrevRank: "I", PrevLP: 420},
+			current:  riotapi.LeagueEntry{Tier: "GRANDMASTER", Rank: "I", LeaguePoints: 420},
+			lpChange: 0,
+			want:     s.RankEventLadder,
+		},
+		{
+			name:     "season reset",
+			prev:     s.PreviousRank{PrevTier: "PLATINUM", PrevRank: "I", PrevLP: 60},
+			current:  riotapi.LeagueEntry{Tier: "UNRANKED"},
+			lpChange: -60,
+			want:     s.RankEventSeasonReset,
+		},
+		{
+			name:     "LP given back by Riot",
+			prev:     s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 20},
+			current:  riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 40},
+			lpChange: 20,
+			want:     s.RankEventCorrection,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRankEvent(&tt.prev, &tt.current, tt.lpChange); got != tt.want {
+				t.Errorf("classifyRankEvent() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+// rankMilestone is a change of division or tier worth its own announcement.
+type rankMilestone int
+
+const (
+	milestoneNone rankMilestone = iota
+	milestoneDivisionUp
+	milestoneTierUp
+	// milestoneSeasonPeak is a tier reached for the first time this season
+	milestoneSeasonPeak
+	milestoneDivisionDown
+	milestoneTierDown
+)
+
+// classifyRankMilestone compares the rank before and after a match. peak is the season peak before the match,
+// nil when unknown, in which case a new tier can't be told to be a first.
+func classifyRankMilestone(prev *s.PreviousRank, current *riotapi.LeagueEntry, peak *s.PreviousRank) rankMilestone {
+	tierDiff := u.TierValue(current.Tier) - u.TierValue(prev.PrevTier)
+
+	switch {
+	case tierDiff > 0 && peak != nil && u.TierValue(current.Tier) > u.TierValue(peak.PrevTier):
+		return milestoneSeasonPeak
+	case tierDiff > 0:
+		return milestoneTierUp
+	case tierDiff < 0:
+		return milestoneTierDown
+	}
+
+	switch cmp := u.GetRankValue(current.Rank) - u.GetRankValue(prev.PrevRank); {
+	case cmp > 0:
+		return milestoneDivisionUp
+	case cmp < 0:
+		return milestoneDivisionDown
+	}
+
+	return milestoneNone
+}
+
+// recordSeasonPeak stores a ranked rank as the season peak of a summoner when it is their highest,
+// and returns the peak before it.
+func (b *Bot) recordSeasonPeak(ctx context.Context, summoner riotapi.Summoner, summonerUUID uuid.UUID, current *riotapi.LeagueEntry) *s.PreviousRank {
+	if u.TierValue(current.Tier) < 0 {
+		return nil
+	}
+
+	peak, err := b.storage.RecordSeasonPeak(ctx, summonerUUID, current.QueueType, current.Tier, current.Rank, current.LeaguePoints)
+	if err != nil {
+		log.Printf("Error recording season peak of %s: %v", summoner.Name, err)
+		return nil
+	}
+
+	return peak
+}
+
+// checkRankMilestone records the season peak of a summoner after a match and returns the announcement of the
+// promotion or demotion the match caused, nil if it didn't change their division.
+func (b *Bot) checkRankMilestone(ctx context.Context, summoner riotapi.Summoner, summonerUUID uuid.UUID, prev *s.PreviousRank, current *riotapi.LeagueEntry) *dg.MessageEmbed {
+	peak := b.recordSeasonPeak(ctx, summoner, summonerUUID, current)
+
+	milestone := classifyRankMilestone(prev, current, peak)
+	if milestone == milestoneNone {
+		return nil
+	}
+
+	return b.prepareRankMilestoneEmbed(summoner, milestone, current)
+}
+
+// prepareRankMilestoneEmbed returns an embed celebrating a promotion, or mourning a demotion.
+func (b *Bot) prepareRankMilestoneEmbed(summoner riotapi.Summoner, milestone rankMilestone, current *riotapi.LeagueEntry) *dg.MessageEmbed {
+	rank := current.Tier
+	if !u.IsApexTier(current.Tier) {
+		rank = fmt.Sprintf("%s %s", current.Tier, current.Rank)
+	}
+	tierName := u.CapitalizeFirst(strings.ToLower(current.Tier))
+
+	var title, description string
+	color := u.GetRankColor(current.Tier)
+	switch milestone {
+	case milestoneDivisionUp:
+		title = fmt.Sprintf("⬆️ %s promoted to %s", summoner.Name, rank)
+		description = "One division closer to the next tier!"
+	case milestoneTierUp:
+		title = fmt.Sprintf("🎉 %s reached %s", summoner.Name, tierName)
+		description = fmt.Sprintf("Welcome (back) to %s!", tierName)
+	case milestoneSeasonPeak:
+		title = fmt.Sprintf("🏔️ %s reached %s for the first time this season", summoner.Name, tierName)
+		description = "New season peak! 🎊"
+	case milestoneDivisionDown:
+		title = fmt.Sprintf("⬇️ %s demoted to %s", summoner.Name, rank)
+		description = "A setback, the division will be back soon."
+		color = 0x992D22
+	case milestoneTierDown:
+		title = fmt.Sprintf("💔 %s fell to %s", summoner.Name, tierName)
+		description = fmt.Sprintf("Demoted to %s, time for a comeback.", rank)
+		color = 0x992D22
+	}
+
+	return &dg.MessageEmbed{
+		Title:       title,
+		Color:       color,
+		Description: description,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueTypeName(current.QueueType),
+		},
+		Thumbnail: thumbnail(b.ddragon.ProfileIconURL(summoner.ProfileIconID)),
+		Footer: &dg.MessageEmbedFooter{
+			Text: b.formatRankWithLadder(summoner, current),
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"testing"
+
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+)
+
+func TestClassifyRankMilestone(t *testing.T) {
+	tests := []struct {
+		name    string
+		prev    s.PreviousRank
+		current riotapi.LeagueEntry
+		peak    *s.PreviousRank
+		want    rankMilestone
+	}{
+		{
+			name:    "LP gained in the same division",
+			prev:    s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 40},
+			current: riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 62},
+			want:    milestoneNone,
+		},
+		{
+			name:    "division up",
+			prev:    s.PreviousRank{PrevTier: "GOLD", PrevRank: "II", PrevLP: 90},
+			current: riotapi.LeagueEntry{Tier: "GOLD", Rank: "I", LeaguePoints: 10},
+			want:    milestoneDivisionUp,
+		},
+		{
+			name:    "division down",
+			prev:    s.PreviousRank{PrevTier: "GOLD", PrevRank: "I", PrevLP: 0},
+			current: riotapi.LeagueEntry{Tier: "GOLD", Rank: "II", LeaguePoints: 75},
+			want:    milestoneDivisionDown,
+		},
+		{
+			name:    "tier reached again this season",
+			prev:    s.PreviousRank{PrevTier: "GOLD", PrevRank: "I", PrevLP: 95},
+			current: riotapi.LeagueEntry{Tier: "PLATINUM", Rank: "IV", LeaguePoints: 15},
+			peak:    &s.PreviousRank{PrevTier: "PLATINUM", PrevRank: "III", PrevLP: 30},
+			want:    milestoneTierUp,
+		},
+		{
+			name:    "tier reached for the first time this season",
+			prev:    s.PreviousRank{PrevTier: "GOLD", PrevRank: "I", PrevLP: 95},
+			current: riotapi.LeagueEntry{Tier: "PLATINUM", Rank: "IV", LeaguePoints: 15},
+			peak:    &s.PreviousRank{PrevTier: "GOLD", PrevRank: "I", PrevLP: 95},
+			want:    milestoneSeasonPeak,
+		},
+		{
+			name:    "new tier with an unknown peak",
+			prev:    s.PreviousRank{PrevTier: "GOLD", PrevRank: "I", PrevLP: 95},
+			current: riotapi.LeagueEntry{Tier: "PLATINUM", Rank: "IV", LeaguePoints: 15},
+			want:    milestoneTierUp,
+		},
+		{
+			name:    "tier down",
+			prev:    s.PreviousRank{PrevTier: "PLATINUM", PrevRank: "IV", PrevLP: 0},
+			current: riotapi.LeagueEntry{Tier: "GOLD", Rank: "I", LeaguePoints: 70},
+			peak:    &s.PreviousRank{PrevTier: "PLATINUM", PrevRank: "IV", PrevLP: 15},
+			want:    milestoneTierDown,
+		},
+		{
+			name:    "Master LP",
+			prev:    s.PreviousRank{PrevTier: "MASTER", PrevRank: "I", PrevLP: 120},
+			current: riotapi.LeagueEntry{Tier: "MASTER", Rank: "I", LeaguePoints: 140},
+			peak:    &s.PreviousRank{PrevTier: "MASTER", PrevRank: "I", PrevLP: 150},
+			want:    milestoneNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRankMilestone(&tt.prev, &tt.current, tt.peak); got != tt.want {
+				t.Errorf("classifyRankMilestone() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	dg "github.com/bwmarrin/discordgo"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+// seasonSummaryMaxLines caps the summoners listed per queue in an end-of-split summary, an embed description
+// holds 4096 characters.
+const seasonSummaryMaxLines = 30
+
+// seasonSummaryQueues are the queues summed up at the end of a split, in this order.
+var seasonSummaryQueues = []string{riotapi.QueueTypeRankedSolo, riotapi.QueueTypeRankedFlex}
+
+// checkSeasonRollover rolls over to the split in progress when it isn't the last one the bot saw start:
+// final ranks are snapshotted, placements start over and every guild gets an end-of-split summary.
+// The first check only records the split in progress.
+func (b *Bot) checkSeasonRollover() {
+	current := b.storage.GetCurrentSeason()
+
+	last, err := b.storage.GetLastSeasonRollover(b.ctx)
+	if err != nil {
+		log.Printf("Error checking season rollover: %v", err)
+		return
+	}
+	if last == current.String() {
+		return
+	}
+
+	rolledOver, err := b.storage.RollOverSeason(b.ctx, last, current.String())
+	if err != nil {
+		log.Printf("Error rolling over to %s: %v", current, err)
+		return
+	}
+	if !rolledOver || last == "" {
+		return
+	}
+
+	log.Printf("🌱 Split %s started on %s, final ranks of %s recorded", current, current.Start.Format("2006-01-02"), last)
+
+	b.announceSeasonSummaries(last)
+}
+
+// announceSeasonSummaries posts the final ranks of a split in every guild with a channel set.
+func (b *Bot) announceSeasonSummaries(seasonKey string) {
+	guilds, err := b.storage.GetGuildsWithChannel(b.ctx)
+	if err != nil {
+		log.Printf("Error fetching guilds for the %s summary: %v", seasonKey, err)
+		return
+	}
+
+	for _, guild := range guilds {
+		results, err := b.storage.GetSeasonResults(b.ctx, guild.ID, seasonKey)
+		if err != nil {
+			log.Printf("Error fetching %s results of guild %s: %v", seasonKey, guild.ID, err)
+			continue
+		}
+
+		for _, embed := range prepareSeasonSummaryEmbeds(seasonKey, results) {
+			if err := b.announceNewMatch(guild.ID, embed); err != nil {
+				log.Printf("Error announcing %s summary in guild %s: %v", seasonKey, guild.ID, err)
+			}
+		}
+	}
+}
+
+// prepareSeasonSummaryEmbeds returns an embed per ranked queue listing the final ranks of a split, highest first,
+// with the peak of each summoner when it was higher.
+func prepareSeasonSummaryEmbeds(seasonKey string, results []s.SeasonResult) []*dg.MessageEmbed {
+	var embeds []*dg.MessageEmbed
+
+	for _, queueType := range seasonSummaryQueues {
+		var queueResults []s.SeasonResult
+		for _, result := range results {
+			if result.QueueType == queueType {
+				queueResults = append(queueResults, result)
+			}
+		}
+		if len(queueResults) == 0 {
+			continue
+		}
+
+		sort.SliceStable(queueResults, func(i, j int) bool {
+			a, b := queueResults[i], queueResults[j]
+			if cmp := u.CompareRanks(a.Tier, a.Rank, b.Tier, b.Rank); cmp != 0 {
+				return cmp > 0
+			}
+			return a.LeaguePoints > b.LeaguePoints
+		})
+
+		var lines []string
+		for idx, result := range queueResults {
+			if idx == seasonSummaryMaxLines {
+				lines = append(lines, fmt.Sprintf("… and %d more", len(queueResults)-idx))
+				break
+			}
+
+			line := fmt.Sprintf("%s **%s** • %s", u.GetSummaryRankDisplay(idx+1), result.SummonerName, u.FormatRank(result.Tier, result.Rank, result.LeaguePoints))
+			peakCmp := u.CompareRanks(result.PeakTier, result.PeakRank, result.Tier, result.Rank)
+			if result.PeakTier != "" && (peakCmp > 0 || (peakCmp == 0 && result.PeakLeaguePoints > result.LeaguePoints)) {
+				line = fmt.Sprintf("%s (peak %s)", line, u.FormatRank(result.PeakTier, result.PeakRank, result.PeakLeaguePoints))
+			}
+			lines = append(lines, line)
+		}
+
+		embeds = append(embeds, &dg.MessageEmbed{
+			Title:       fmt.Sprintf("🏁 End of split %s", seasonKey),
+			Color:       u.GetRankColor(queueResults[0].Tier),
+			Description: strings.Join(lines, "\n"),
+			Author: &dg.MessageEmbedAuthor{
+				Name: riotapi.QueueTypeName(queueType),
+			},
+			Footer: &dg.MessageEmbedFooter{
+				Text: "Final ranks • placements start over for the new split",
+			},
+		})
+	}
+
+	return embeds
+}
@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	dg "github.com/bwmarrin/discordgo"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	s "github.com/tristan-derez/league-tracker/internal/storage"
+	u "github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+const (
+	// statusPollInterval is how often the status of the configured platform is checked.
+	statusPollInterval = 5 * time.Minute
+	// statusCheckTimeout bounds a single status check, announcements included.
+	statusCheckTimeout = time.Minute
+)
+
+// downRegions is the set of platforms whose match history is unavailable. The zero value is an empty set,
+// safe for concurrent use.
+type downRegions struct {
+	mu      sync.RWMutex
+	regions map[string]bool
+}
+
+// has reports whether the match history of region is unavailable.
+func (d *downRegions) has(region string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.regions[region]
+}
+
+// set records whether the match history of region is unavailable, and reports whether that changed.
+func (d *downRegions) set(region string, down bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.regions[region] == down {
+		return false
+	}
+
+	if d.regions == nil {
+		d.regions = make(map[string]bool)
+	}
+	if down {
+		d.regions[region] = true
+	} else {
+		delete(d.regions, region)
+	}
+
+	return true
+}
+
+// TrackRiotStatus continuously checks the lol-status-v4 data of the configured platform and of every platform
+// summoners are tracked on. New incidents and maintenance windows are posted once in every guild with an update
+// channel tracking summoners of the platform (every guild for the configured one), and match tracking of a platform
+// is paused while its match history is unavailable.
+func (b *Bot) TrackRiotStatus() {
+	b.checkRiotStatus()
+
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			log.Println("Stopping Riot status tracking")
+			return
+		case <-ticker.C:
+			b.checkRiotStatus()
+		}
+	}
+}
+
+// checkRiotStatus checks the status of the configured platform and of every platform summoners are tracked on.
+func (b *Bot) checkRiotStatus() {
+	if !b.isLeader() || b.riotClient.KeyInvalid() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(b.ctx, statusCheckTimeout)
+	defer cancel()
+
+	regions, err := b.storage.GetTrackedRegions(ctx)
+	if err != nil {
+		log.Printf("Error fetching tracked regions: %v", err)
+	}
+	if !slices.Contains(regions, b.config.RiotAPIRegion) {
+		regions = append(regions, b.config.RiotAPIRegion)
+	}
+
+	for _, region := range regions {
+		if ctx.Err() != nil {
+			return
+		}
+		b.checkRegionStatus(ctx, region)
+	}
+}
+
+// checkRegionStatus fetches the status of a platform, pauses or resumes its match tracking,
+// and announces the incidents and maintenance windows guilds weren't told about.
+func (b *Bot) checkRegionStatus(ctx context.Context, region string) {
+	status, err := b.riotClient.GetPlatformStatus(ctx, region)
+	if err != nil {
+		log.Printf("Error fetching Riot status of %s: %v", region, err)
+		return
+	}
+
+	incidents := append(status.Incidents, status.Maintenances...)
+
+	matchHistoryDown := false
+	for _, incident := range incidents {
+		if incident.AffectsMatchHistory() {
+			matchHistoryDown = true
+		}
+	}
+
+	if b.matchHistoryDown.set(region, matchHistoryDown) {
+		if matchHistoryDown {
+			log.Printf("Riot reports match history unavailable on %s, pausing match tracking there", status.Name)
+		} else {
+			log.Printf("Match history is back on %s, resuming match tracking there", status.Name)
+		}
+	}
+
+	if len(incidents) == 0 {
+		return
+	}
+
+	var guilds []s.Guild
+	if region == b.config.RiotAPIRegion {
+		guilds, err = b.storage.GetGuildsWithChannel(ctx)
+	} else {
+		guilds, err = b.storage.GetGuildsWithChannelInRegion(ctx, region)
+	}
+	if err != nil {
+		log.Printf("Error fetching guilds: %v", err)
+		return
+	}
+
+	for _, incident := range incidents {
+		embed := prepareStatusEmbed(status, incident)
+		statusType := incident.Type()
+
+		for _, guild := range guilds {
+			announced, err := b.storage.HasStatusAnnouncement(ctx, guild.ID, statusType, incident.ID)
+			if err != nil {
+				log.Printf("Error checking status %s %d in guild %s: %v", statusType, incident.ID, guild.ID, err)
+				continue
+			}
+
+			if announced {
+				continue
+			}
+
+			if err := b.announceNewMatch(guild.ID, embed); err != nil {
+				log.Printf("Error announcing Riot status %s %d in guild %s: %v", statusType, incident.ID, guild.ID, err)
+				continue
+			}
+
+			if err := b.storage.AddStatusAnnouncement(ctx, guild.ID, statusType, incident.ID); err != nil {
+				log.Printf("Error storing status %s %d in guild %s: %v", statusType, incident.ID, guild.ID, err)
+			}
+		}
+	}
+}
+
+// prepareStatusEmbed returns an embed describing an incident or a maintenance window of a platform.
+func prepareStatusEmbed(status *riotapi.PlatformStatus, incident riotapi.StatusIncident) *dg.MessageEmbed {
+	title := fmt.Sprintf("⚠️ %s", incident.Title())
+	if incident.MaintenanceStatus != "" {
+		title = fmt.Sprintf("🛠️ Maintenance: %s", incident.Title())
+	}
+
+	description := incident.LatestUpdate()
+	if incident.AffectsMatchHistory() {
+		description = strings.TrimSpace(fmt.Sprintf("%s\n\nNew matches will be announced once match history is back.", description))
+	}
+
+	footer := status.Name
+	if createdAt, err := time.Parse(time.RFC3339, incident.CreatedAt); err == nil {
+		footer = fmt.Sprintf("%s • %s", status.Name, u.FormatTime(createdAt.UnixMilli()))
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       title,
+		Description: description,
+		Color:       statusColor(incident.IncidentSeverity),
+		Author: &dg.MessageEmbedAuthor{
+			Name: "Riot Games status",
+			URL:  fmt.Sprintf("https://status.riotgames.com/lol?region=%s", strings.ToLower(status.ID)),
+		},
+		Footer: &dg.MessageEmbedFooter{
+			Text: footer,
+		},
+	}
+
+	return embed
+}
+
+// statusColor returns the embed color of an incident severity.
+func statusColor(severity string) int {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return 0xFF0000
+	case "warning":
+		return 0xFFA500
+	default:
+		return 0x3498DB
+	}
+}
@@ -0,0 +1,141 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+)
+
+// streakLookback is the number of matches read to measure a streak, longer streaks are shown as this length.
+const streakLookback = 100
+
+// streak is a run of consecutive wins or losses in a queue, remakes left out.
+type streak struct {
+	win    bool
+	length int
+}
+
+// String returns the streak as shown in embeds, e.g. "🔥 5 wins in a row" or "🧊 4 losses in a row".
+func (st streak) String() string {
+	if st.win {
+		return fmt.Sprintf("🔥 %d wins in a row", st.length)
+	}
+
+	return fmt.Sprintf("🧊 %d losses in a row", st.length)
+}
+
+// currentStreak returns the streak the results end with. results are newest first.
+func currentStreak(results []bool) streak {
+	if len(results) == 0 {
+		return streak{}
+	}
+
+	st := streak{win: results[0]}
+	for _, win := range results {
+		if win != st.win {
+			break
+		}
+		st.length++
+	}
+
+	return st
+}
+
+// matchStreaks returns the streak of a summoner in the queue of a match, stored beforehand, and the streak they
+// were on before it. ok is false for remakes, which don't affect streaks.
+func (b *Bot) matchStreaks(ctx context.Context, summonerUUID uuid.UUID, match *riotapi.MatchData) (current, previous streak, ok bool, err error) {
+	if isRemake(match) {
+		return streak{}, streak{}, false, nil
+	}
+
+	results, err := b.storage.GetRecentResults(ctx, summonerUUID, match.QueueID, remakeMaxDuration, streakLookback)
+	if err != nil {
+		return streak{}, streak{}, false, err
+	}
+
+	if len(results) == 0 {
+		return streak{}, streak{}, false, nil
+	}
+
+	return currentStreak(results), currentStreak(results[1:]), true, nil
+}
+
+// addStreak shows the streak a match extends on its embed, once it is as long as the lowest announced threshold,
+// and returns the announcement of the streak crossing a threshold or being broken by the match, nil if there is none.
+func (b *Bot) addStreak(ctx context.Context, summoner riotapi.Summoner, summonerUUID uuid.UUID, match *riotapi.MatchData, embed *dg.MessageEmbed) *dg.MessageEmbed {
+	thresholds := b.config.StreakThresholds
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	current, previous, ok, err := b.matchStreaks(ctx, summonerUUID, match)
+	if err != nil {
+		log.Printf("Error computing streak of %s: %v", summoner.Name, err)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	if current.length >= thresholds[0] {
+		embed.Fields = append(embed.Fields, &dg.MessageEmbedField{
+			Name:  "Streak",
+			Value: current.String(),
+		})
+	}
+
+	switch {
+	case slices.Contains(thresholds, current.length):
+		return b.prepareStreakEmbed(summoner, match, current)
+	case current.length == 1 && previous.length >= thresholds[0]:
+		return b.prepareStreakBrokenEmbed(summoner, match, previous)
+	}
+
+	return nil
+}
+
+// prepareStreakEmbed returns an embed announcing that a summoner reached a streak threshold.
+func (b *Bot) prepareStreakEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, st streak) *dg.MessageEmbed {
+	title := fmt.Sprintf("%s is on fire! 🔥", summoner.Name)
+	color := 0xFF8C00
+	if !st.win {
+		title = fmt.Sprintf("%s can't buy a win 🧊", summoner.Name)
+		color = 0x5DADE2
+	}
+
+	return &dg.MessageEmbed{
+		Title:       title,
+		Color:       color,
+		Description: st.String(),
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
+		},
+		Thumbnail: thumbnail(b.ddragon.ProfileIconURL(summoner.ProfileIconID)),
+	}
+}
+
+// prepareStreakBrokenEmbed returns an embed announcing that a match ended a streak at least as long as the
+// lowest threshold.
+func (b *Bot) prepareStreakBrokenEmbed(summoner riotapi.Summoner, match *riotapi.MatchData, broken streak) *dg.MessageEmbed {
+	description := fmt.Sprintf("A loss ended a streak of %d wins", broken.length)
+	color := 0x808080
+	if !broken.win {
+		description = fmt.Sprintf("A win ended a streak of %d losses", broken.length)
+		color = 0x00FF00
+	}
+
+	return &dg.MessageEmbed{
+		Title:       fmt.Sprintf("%s's streak is over", summoner.Name),
+		Color:       color,
+		Description: description,
+		Author: &dg.MessageEmbedAuthor{
+			Name: riotapi.QueueName(match.QueueID),
+		},
+		Thumbnail: thumbnail(b.ddragon.ProfileIconURL(summoner.ProfileIconID)),
+	}
+}
@@ -0,0 +1,41 @@
+package bot
+
+import "testing"
+
+func TestCurrentStreak(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []bool
+		want    streak
+	}{
+		{name: "no match", results: nil, want: streak{}},
+		{name: "single win", results: []bool{true}, want: streak{win: true, length: 1}},
+		{name: "wins then a loss", results: []bool{true, true, true, false, true}, want: streak{win: true, length: 3}},
+		{name: "losses", results: []bool{false, false, false, false}, want: streak{win: false, length: 4}},
+		{name: "streak just broken", results: []bool{false, true, true, true, true, true}, want: streak{win: false, length: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := currentStreak(tt.results); got != tt.want {
+				t.Errorf("currentStreak(%v) = %+v, want %+v", tt.results, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreakString(t *testing.T) {
+	tests := []struct {
+		streak streak
+		want   string
+	}{
+		{streak: streak{win: true, length: 5}, want: "🔥 5 wins in a row"},
+		{streak: streak{win: false, length: 4}, want: "🧊 4 losses in a row"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.streak.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.streak, got, tt.want)
+		}
+	}
+}
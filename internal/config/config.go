@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -17,8 +20,39 @@ type Config struct {
 	DBPassword    string
 	DBDatabase    string
 	DBSchema      string
+	// DDragonCacheDir is where Data Dragon files are cached, optional
+	DDragonCacheDir string
+	// RiotHTTPMode is "record" to write Riot API responses to RiotFixturesDir, "replay" to serve them back
+	// without network access, or empty to use the Riot API normally
+	RiotHTTPMode    string
+	RiotFixturesDir string
+	// RiotBaseURL replaces the Riot API hosts (e.g. a local mock server), optional
+	RiotBaseURL string
+	// RiotAPIKeyFile holds the Riot API key instead of RIOT_API and is watched for a new key, optional
+	RiotAPIKeyFile string
+	// BotOwnerID is the Discord user told when the Riot API key expires and allowed to use /apikey, optional
+	BotOwnerID string
+	// AdminChannelID is a Discord channel told when the Riot API key expires, optional
+	AdminChannelID string
+	// StreakThresholds are the win or loss streak lengths announced, ascending, optional (3, 5, 10)
+	StreakThresholds []int
+	// ShardID is the Discord gateway shard of this process, out of ShardCount, optional (0 of 1)
+	ShardID    int
+	ShardCount int
+	// SeasonCalendarFile is a JSON file listing the start of every ranked split, optional (see season.LoadCalendar)
+	SeasonCalendarFile string
 }
 
+const (
+	// defaultDDragonCacheDir is used when DDRAGON_CACHE_DIR isn't set.
+	defaultDDragonCacheDir = ".cache/ddragon"
+	// defaultRiotFixturesDir is used when RIOT_FIXTURES_DIR isn't set.
+	defaultRiotFixturesDir = "testdata/riot-fixtures"
+)
+
+// defaultStreakThresholds is used when STREAK_THRESHOLDS isn't set.
+var defaultStreakThresholds = []int{3, 5, 10}
+
 // Load reads environment variables from a .env file and populates a Config struct.
 // It returns a pointer to the populated Config and any error encountered during the process.
 func Load() (*Config, error) {
@@ -38,6 +72,56 @@ func Load() (*Config, error) {
 		DBSchema:      os.Getenv("DB_SCHEMA"),
 	}
 
+	config.DDragonCacheDir = os.Getenv("DDRAGON_CACHE_DIR")
+	if config.DDragonCacheDir == "" {
+		config.DDragonCacheDir = defaultDDragonCacheDir
+	}
+
+	config.RiotHTTPMode = os.Getenv("RIOT_HTTP_MODE")
+	config.RiotBaseURL = os.Getenv("RIOT_BASE_URL")
+	config.RiotFixturesDir = os.Getenv("RIOT_FIXTURES_DIR")
+	if config.RiotFixturesDir == "" {
+		config.RiotFixturesDir = defaultRiotFixturesDir
+	}
+
+	config.BotOwnerID = os.Getenv("BOT_OWNER_ID")
+	config.AdminChannelID = os.Getenv("ADMIN_CHANNEL_ID")
+	config.RiotAPIKeyFile = os.Getenv("RIOT_API_KEY_FILE")
+	if config.RiotAPIKeyFile != "" {
+		apiKey, err := ReadAPIKeyFile(config.RiotAPIKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RiotAPIKey = apiKey
+	}
+
+	config.SeasonCalendarFile = os.Getenv("SEASON_CALENDAR_FILE")
+
+	config.StreakThresholds = defaultStreakThresholds
+	if thresholds := os.Getenv("STREAK_THRESHOLDS"); thresholds != "" {
+		parsed, err := parseStreakThresholds(thresholds)
+		if err != nil {
+			return nil, err
+		}
+		config.StreakThresholds = parsed
+	}
+
+	config.ShardCount = 1
+	if shardCount := os.Getenv("SHARD_COUNT"); shardCount != "" {
+		count, err := strconv.Atoi(shardCount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SHARD_COUNT '%s': %w", shardCount, err)
+		}
+		config.ShardCount = count
+	}
+	if shardID := os.Getenv("SHARD_ID"); shardID != "" {
+		id, err := strconv.Atoi(shardID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SHARD_ID '%s': %w", shardID, err)
+		}
+		config.ShardID = id
+	}
+
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
@@ -72,5 +156,42 @@ func (c *Config) validate() error {
 		return fmt.Errorf("missing required environment variables: %v", missingVars)
 	}
 
+	if c.ShardCount < 1 || c.ShardID < 0 || c.ShardID >= c.ShardCount {
+		return fmt.Errorf("invalid SHARD_ID %d for SHARD_COUNT %d, expected 0 <= SHARD_ID < SHARD_COUNT", c.ShardID, c.ShardCount)
+	}
+
+	switch c.RiotHTTPMode {
+	case "", "record", "replay":
+	default:
+		return fmt.Errorf("invalid RIOT_HTTP_MODE '%s', expected record or replay", c.RiotHTTPMode)
+	}
+
 	return nil
 }
+
+// ReadAPIKeyFile returns the Riot API key stored in a file, surrounding whitespace removed.
+func ReadAPIKeyFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading Riot API key file: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// parseStreakThresholds parses a comma-separated list of streak lengths (e.g. "3, 5, 10"), sorted ascending.
+// A streak starts at 2 matches, shorter thresholds are refused.
+func parseStreakThresholds(value string) ([]int, error) {
+	var thresholds []int
+	for _, part := range strings.Split(value, ",") {
+		threshold, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || threshold < 2 {
+			return nil, fmt.Errorf("invalid STREAK_THRESHOLDS '%s', expected streak lengths of at least 2 (e.g. 3, 5, 10)", value)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+
+	slices.Sort(thresholds)
+
+	return slices.Compact(thresholds), nil
+}
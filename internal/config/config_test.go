@@ -0,0 +1,34 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseStreakThresholds(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []int
+		wantErr bool
+	}{
+		{value: "3,5,10", want: []int{3, 5, 10}},
+		{value: " 10, 3 , 5 ", want: []int{3, 5, 10}},
+		{value: "5,3,5", want: []int{3, 5}},
+		{value: "2", want: []int{2}},
+		{value: "1,3", wantErr: true},
+		{value: "3,,5", wantErr: true},
+		{value: "three", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseStreakThresholds(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStreakThresholds(%q) error = %v, want error %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseStreakThresholds(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
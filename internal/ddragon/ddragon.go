@@ -0,0 +1,269 @@
+// Package ddragon fetches the static data of League of Legends (champions, items, runes, summoner spells and
+// profile icons) from Data Dragon and keeps it cached on disk, so names and images can be resolved offline.
+package ddragon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	baseURL  = "https://ddragon.leagueoflegends.com"
+	language = "en_US"
+)
+
+// dataFiles are the Data Dragon files cached for every version.
+var dataFiles = []string{"champion.json", "item.json", "runesReforged.json", "summoner.json", "profileicon.json"}
+
+// Client resolves static data from the latest Data Dragon version.
+// It is safe for concurrent use, the data of a version is swapped at once when a new version is loaded.
+type Client struct {
+	httpClient *http.Client
+	cacheDir   string
+
+	mu   sync.RWMutex
+	data *staticData
+}
+
+// New creates a Client caching the Data Dragon files in cacheDir.
+// No data is available until Refresh succeeded once.
+func New(cacheDir string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		cacheDir: cacheDir,
+	}
+}
+
+// Version returns the Data Dragon version currently loaded, or "" if none could be loaded yet.
+func (c *Client) Version() string {
+	if data := c.current(); data != nil {
+		return data.version
+	}
+
+	return ""
+}
+
+// RefreshPeriodically checks for a new Data Dragon version every interval until ctx is done.
+func (c *Client) RefreshPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil {
+				log.Printf("Error refreshing Data Dragon data: %v", err)
+			}
+		}
+	}
+}
+
+// Refresh loads the data of the latest Data Dragon version, downloading the files that aren't cached yet.
+// When Data Dragon can't be reached, the data already loaded is kept, or the newest cached version is loaded.
+func (c *Client) Refresh(ctx context.Context) error {
+	version, err := c.fetchLatestVersion(ctx)
+	if err != nil {
+		if c.current() != nil {
+			return err
+		}
+
+		log.Printf("Warning: %v. Using the Data Dragon cache", err)
+		return c.loadCachedVersion()
+	}
+
+	if current := c.current(); current != nil && current.version == version {
+		return nil
+	}
+
+	data, err := c.loadVersion(ctx, version)
+	if err != nil {
+		if c.current() != nil {
+			return err
+		}
+
+		log.Printf("Warning: %v. Using the Data Dragon cache", err)
+		return c.loadCachedVersion()
+	}
+
+	c.setCurrent(data)
+	log.Printf("Data Dragon data loaded for version %s", version)
+
+	return nil
+}
+
+func (c *Client) current() *staticData {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.data
+}
+
+func (c *Client) setCurrent(data *staticData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data = data
+}
+
+// fetchLatestVersion returns the newest Data Dragon version. The cache keeps one directory per version
+// downloaded, which is all loadCachedVersion needs when Data Dragon can't be reached.
+func (c *Client) fetchLatestVersion(ctx context.Context) (string, error) {
+	body, err := c.get(ctx, baseURL+"/api/versions.json")
+	if err != nil {
+		return "", fmt.Errorf("error fetching Data Dragon versions: %w", err)
+	}
+
+	var versions []string
+	if err := json.Unmarshal(body, &versions); err != nil {
+		return "", fmt.Errorf("error unmarshaling Data Dragon versions: %w", err)
+	}
+
+	if len(versions) == 0 {
+		return "", errors.New("no Data Dragon version found in the response")
+	}
+
+	return versions[0], nil
+}
+
+// loadCachedVersion loads the newest version found in the cache, without any request.
+func (c *Client) loadCachedVersion() error {
+	entries, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		return fmt.Errorf("error reading Data Dragon cache: %w", err)
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			versions = append(versions, entry.Name())
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+
+	for _, version := range versions {
+		data, err := c.readVersion(version)
+		if err != nil {
+			log.Printf("Skipping Data Dragon cache of version %s: %v", version, err)
+			continue
+		}
+
+		c.setCurrent(data)
+		log.Printf("Data Dragon data loaded from cache for version %s", version)
+		return nil
+	}
+
+	return errors.New("no Data Dragon version in the cache")
+}
+
+// loadVersion downloads the files of a version missing from the cache, then reads them.
+func (c *Client) loadVersion(ctx context.Context, version string) (*staticData, error) {
+	for _, file := range dataFiles {
+		path := filepath.Join(c.cacheDir, version, file)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		body, err := c.get(ctx, fmt.Sprintf("%s/cdn/%s/data/%s/%s", baseURL, version, language, file))
+		if err != nil {
+			return nil, fmt.Errorf("error downloading %s of version %s: %w", file, version, err)
+		}
+
+		if err := writeFile(path, body); err != nil {
+			return nil, fmt.Errorf("error caching %s of version %s: %w", file, version, err)
+		}
+	}
+
+	return c.readVersion(version)
+}
+
+// readVersion reads the cached files of a version.
+func (c *Client) readVersion(version string) (*staticData, error) {
+	files := make(map[string][]byte, len(dataFiles))
+	for _, file := range dataFiles {
+		body, err := os.ReadFile(filepath.Join(c.cacheDir, version, file))
+		if err != nil {
+			return nil, err
+		}
+		files[file] = body
+	}
+
+	return parseStaticData(version, files)
+}
+
+// get performs a GET request on Data Dragon and returns the body of the response.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	return body, nil
+}
+
+// writeFile writes a file through a temporary file, so a crash never leaves a truncated file in the cache.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// compareVersions compares two Data Dragon versions (e.g. 14.15.1) part by part.
+// It returns a negative number when a is older than b, a positive one when it is newer and 0 when they're equal.
+func compareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aPart, aErr := strconv.Atoi(aParts[i])
+		bPart, bErr := strconv.Atoi(bParts[i])
+		if aErr != nil || bErr != nil {
+			return strings.Compare(aParts[i], bParts[i])
+		}
+		if aPart != bPart {
+			return aPart - bPart
+		}
+	}
+
+	return len(aParts) - len(bParts)
+}
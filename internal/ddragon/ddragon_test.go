@@ -0,0 +1,51 @@
+package ddragon
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "14.15.1", b: "14.15.1", want: 0},
+		{a: "14.15.1", b: "14.9.1", want: 1},
+		{a: "9.24.2", b: "10.1.1", want: -1},
+		{a: "15.1.1", b: "14.24.1", want: 1},
+		{a: "14.15.1", b: "14.15", want: 1},
+		{a: "lolpatch_7.20", b: "14.1.1", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+			if sign(got) != tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := compareVersions(tt.b, tt.a); sign(reverse) != -tt.want {
+				t.Errorf("compareVersions(%q, %q) = %d, want sign %d", tt.b, tt.a, reverse, -tt.want)
+			}
+		})
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func TestLoadCachedVersion(t *testing.T) {
+	client := New("testdata")
+	if err := client.loadCachedVersion(); err != nil {
+		t.Fatalf("loadCachedVersion: %v", err)
+	}
+
+	if version := client.Version(); version != "15.1.1" {
+		t.Errorf("Version() = %q, want 15.1.1", version)
+	}
+}
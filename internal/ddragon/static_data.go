@@ -0,0 +1,281 @@
+package ddragon
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Champion is a champion of Data Dragon.
+type Champion struct {
+	// ID is the name used in image URLs (e.g. MonkeyKing)
+	ID string
+	// Key is the numeric id used by match-v5, spectator-v5 and champion-mastery-v4
+	Key int
+	// Name is the display name (e.g. Wukong)
+	Name string
+}
+
+// Item is an item of Data Dragon.
+type Item struct {
+	ID    int
+	Name  string
+	image string
+}
+
+// Rune is a rune (or rune path) of Data Dragon.
+type Rune struct {
+	ID   int
+	Key  string
+	Name string
+	icon string
+}
+
+// SummonerSpell is a summoner spell of Data Dragon.
+type SummonerSpell struct {
+	// ID is the name used in image URLs (e.g. SummonerFlash)
+	ID string
+	// Key is the numeric id used by match-v5 and spectator-v5
+	Key  int
+	Name string
+}
+
+// staticData is the data of a single Data Dragon version.
+type staticData struct {
+	version string
+	// champions are indexed by their lowercased ID, match-v5 doesn't always use the same case (e.g. FiddleSticks)
+	champions      map[string]Champion
+	championsByKey map[int]Champion
+	items          map[int]Item
+	runes          map[int]Rune
+	summonerSpells map[int]SummonerSpell
+	profileIcons   map[int]bool
+}
+
+// defaultProfileIconID is the icon shown for profile icons missing from Data Dragon (e.g. newer than the cache).
+const defaultProfileIconID = 29
+
+// Champion returns the champion with the given name, as found in the championName field of match-v5.
+// Unknown champions are returned with the given name as both ID and display name.
+func (c *Client) Champion(name string) Champion {
+	if data := c.current(); data != nil {
+		if champion, ok := data.champions[strings.ToLower(name)]; ok {
+			return champion
+		}
+	}
+
+	return Champion{ID: name, Name: name}
+}
+
+// ChampionByKey returns the champion with the given numeric key.
+// Unknown champions are returned with a "Champion #key" display name.
+func (c *Client) ChampionByKey(key int) Champion {
+	if data := c.current(); data != nil {
+		if champion, ok := data.championsByKey[key]; ok {
+			return champion
+		}
+	}
+
+	return Champion{Key: key, Name: fmt.Sprintf("Champion #%d", key)}
+}
+
+// ChampionName returns the display name of a champion from its match-v5 name (e.g. MonkeyKing -> Wukong).
+func (c *Client) ChampionName(name string) string {
+	return c.Champion(name).Name
+}
+
+// ChampionImageURL returns the square image of a champion, or "" when it can't be resolved.
+func (c *Client) ChampionImageURL(champion Champion) string {
+	version := c.Version()
+	if version == "" || champion.ID == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/cdn/%s/img/champion/%s.png", baseURL, version, champion.ID)
+}
+
+// Item returns the item with the given id, and whether it was found.
+func (c *Client) Item(id int) (Item, bool) {
+	if data := c.current(); data != nil {
+		item, ok := data.items[id]
+		return item, ok
+	}
+
+	return Item{ID: id}, false
+}
+
+// ItemImageURL returns the image of an item, or "" when it can't be resolved.
+func (c *Client) ItemImageURL(id int) string {
+	item, ok := c.Item(id)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/cdn/%s/img/item/%s", baseURL, c.Version(), item.image)
+}
+
+// Rune returns the rune or rune path with the given id, and whether it was found.
+func (c *Client) Rune(id int) (Rune, bool) {
+	if data := c.current(); data != nil {
+		r, ok := data.runes[id]
+		return r, ok
+	}
+
+	return Rune{ID: id}, false
+}
+
+// RuneImageURL returns the icon of a rune or rune path, or "" when it can't be resolved.
+// Rune icons aren't versioned.
+func (c *Client) RuneImageURL(id int) string {
+	r, ok := c.Rune(id)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/cdn/img/%s", baseURL, r.icon)
+}
+
+// SummonerSpell returns the summoner spell with the given numeric key, and whether it was found.
+func (c *Client) SummonerSpell(key int) (SummonerSpell, bool) {
+	if data := c.current(); data != nil {
+		spell, ok := data.summonerSpells[key]
+		return spell, ok
+	}
+
+	return SummonerSpell{Key: key}, false
+}
+
+// SummonerSpellImageURL returns the image of a summoner spell, or "" when it can't be resolved.
+func (c *Client) SummonerSpellImageURL(key int) string {
+	spell, ok := c.SummonerSpell(key)
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/cdn/%s/img/spell/%s.png", baseURL, c.Version(), spell.ID)
+}
+
+// ProfileIconURL returns the image of a profile icon, or "" when no version is loaded.
+// Icons missing from the loaded version are replaced by the default icon.
+func (c *Client) ProfileIconURL(iconID int) string {
+	data := c.current()
+	if data == nil {
+		return ""
+	}
+
+	if !data.profileIcons[iconID] {
+		iconID = defaultProfileIconID
+	}
+
+	return fmt.Sprintf("%s/cdn/%s/img/profileicon/%d.png", baseURL, data.version, iconID)
+}
+
+// parseStaticData parses the Data Dragon files of a version, indexed by file name.
+func parseStaticData(version string, files map[string][]byte) (*staticData, error) {
+	data := &staticData{
+		version:        version,
+		champions:      make(map[string]Champion),
+		championsByKey: make(map[int]Champion),
+		items:          make(map[int]Item),
+		runes:          make(map[int]Rune),
+		summonerSpells: make(map[int]SummonerSpell),
+		profileIcons:   make(map[int]bool),
+	}
+
+	type image struct {
+		Full string `json:"full"`
+	}
+
+	var championsResp struct {
+		Data map[string]struct {
+			ID   string `json:"id"`
+			Key  string `json:"key"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(files["champion.json"], &championsResp); err != nil {
+		return nil, fmt.Errorf("error unmarshaling champions: %w", err)
+	}
+	for _, champion := range championsResp.Data {
+		key, err := strconv.Atoi(champion.Key)
+		if err != nil {
+			continue
+		}
+		data.champions[strings.ToLower(champion.ID)] = Champion{ID: champion.ID, Key: key, Name: champion.Name}
+		data.championsByKey[key] = data.champions[strings.ToLower(champion.ID)]
+	}
+
+	var itemsResp struct {
+		Data map[string]struct {
+			Name  string `json:"name"`
+			Image image  `json:"image"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(files["item.json"], &itemsResp); err != nil {
+		return nil, fmt.Errorf("error unmarshaling items: %w", err)
+	}
+	for id, item := range itemsResp.Data {
+		itemID, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		data.items[itemID] = Item{ID: itemID, Name: item.Name, image: item.Image.Full}
+	}
+
+	type runeResp struct {
+		ID   int    `json:"id"`
+		Key  string `json:"key"`
+		Name string `json:"name"`
+		Icon string `json:"icon"`
+	}
+	var pathsResp []struct {
+		runeResp
+		Slots []struct {
+			Runes []runeResp `json:"runes"`
+		} `json:"slots"`
+	}
+	if err := json.Unmarshal(files["runesReforged.json"], &pathsResp); err != nil {
+		return nil, fmt.Errorf("error unmarshaling runes: %w", err)
+	}
+	for _, path := range pathsResp {
+		data.runes[path.ID] = Rune{ID: path.ID, Key: path.Key, Name: path.Name, icon: path.Icon}
+		for _, slot := range path.Slots {
+			for _, r := range slot.Runes {
+				data.runes[r.ID] = Rune{ID: r.ID, Key: r.Key, Name: r.Name, icon: r.Icon}
+			}
+		}
+	}
+
+	var spellsResp struct {
+		Data map[string]struct {
+			ID   string `json:"id"`
+			Key  string `json:"key"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(files["summoner.json"], &spellsResp); err != nil {
+		return nil, fmt.Errorf("error unmarshaling summoner spells: %w", err)
+	}
+	for _, spell := range spellsResp.Data {
+		key, err := strconv.Atoi(spell.Key)
+		if err != nil {
+			continue
+		}
+		data.summonerSpells[key] = SummonerSpell{ID: spell.ID, Key: key, Name: spell.Name}
+	}
+
+	var iconsResp struct {
+		Data map[string]struct {
+			ID int `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(files["profileicon.json"], &iconsResp); err != nil {
+		return nil, fmt.Errorf("error unmarshaling profile icons: %w", err)
+	}
+	for _, icon := range iconsResp.Data {
+		data.profileIcons[icon.ID] = true
+	}
+
+	return data, nil
+}
@@ -0,0 +1,108 @@
+package ddragon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readTestVersion parses the Data Dragon files of testdata/15.1.1.
+func readTestVersion(t *testing.T) *staticData {
+	t.Helper()
+
+	files := make(map[string][]byte, len(dataFiles))
+	for _, file := range dataFiles {
+		body, err := os.ReadFile(filepath.Join("testdata", "15.1.1", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[file] = body
+	}
+
+	data, err := parseStaticData("15.1.1", files)
+	if err != nil {
+		t.Fatalf("parseStaticData: %v", err)
+	}
+
+	return data
+}
+
+func TestParseStaticData(t *testing.T) {
+	client := New(t.TempDir())
+	client.setCurrent(readTestVersion(t))
+
+	champions := []struct {
+		name string
+		want Champion
+	}{
+		{name: "Ahri", want: Champion{ID: "Ahri", Key: 103, Name: "Ahri"}},
+		{name: "MonkeyKing", want: Champion{ID: "MonkeyKing", Key: 62, Name: "Wukong"}},
+		// match-v5 and Data Dragon don't agree on the case of some champions
+		{name: "FiddleSticks", want: Champion{ID: "Fiddlesticks", Key: 9, Name: "Fiddlesticks"}},
+		// unknown champions keep the name of match-v5
+		{name: "NewChampion", want: Champion{ID: "NewChampion", Name: "NewChampion"}},
+		// entries with an invalid key are skipped
+		{name: "Broken", want: Champion{ID: "Broken", Name: "Broken"}},
+	}
+	for _, tt := range champions {
+		if got := client.Champion(tt.name); got != tt.want {
+			t.Errorf("Champion(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := client.ChampionByKey(62).Name; got != "Wukong" {
+		t.Errorf("ChampionByKey(62).Name = %q, want Wukong", got)
+	}
+	if got := client.ChampionByKey(999).Name; got != "Champion #999" {
+		t.Errorf("ChampionByKey(999).Name = %q, want Champion #999", got)
+	}
+
+	if item, ok := client.Item(3031); !ok || item.Name != "Infinity Edge" {
+		t.Errorf("Item(3031) = %+v, %t, want Infinity Edge", item, ok)
+	}
+	if got := client.ItemImageURL(3031); got != "https://ddragon.leagueoflegends.com/cdn/15.1.1/img/item/3031.png" {
+		t.Errorf("ItemImageURL(3031) = %q", got)
+	}
+
+	// runes are indexed with their paths
+	for _, id := range []int{8100, 8112} {
+		if _, ok := client.Rune(id); !ok {
+			t.Errorf("Rune(%d) not found", id)
+		}
+	}
+	if got := client.RuneImageURL(8112); got != "https://ddragon.leagueoflegends.com/cdn/img/perk-images/Styles/Domination/Electrocute/Electrocute.png" {
+		t.Errorf("RuneImageURL(8112) = %q", got)
+	}
+
+	if spell, ok := client.SummonerSpell(4); !ok || spell.ID != "SummonerFlash" {
+		t.Errorf("SummonerSpell(4) = %+v, %t, want SummonerFlash", spell, ok)
+	}
+
+	icons := []struct {
+		id   int
+		want string
+	}{
+		{id: 4568, want: "https://ddragon.leagueoflegends.com/cdn/15.1.1/img/profileicon/4568.png"},
+		// icons newer than the loaded version fall back to the default one
+		{id: 7000, want: "https://ddragon.leagueoflegends.com/cdn/15.1.1/img/profileicon/29.png"},
+	}
+	for _, tt := range icons {
+		if got := client.ProfileIconURL(tt.id); got != tt.want {
+			t.Errorf("ProfileIconURL(%d) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestParseStaticDataInvalidFile(t *testing.T) {
+	files := map[string][]byte{
+		"champion.json":      []byte(`{"data": {}}`),
+		"item.json":          []byte(`not json`),
+		"runesReforged.json": []byte(`[]`),
+		"summoner.json":      []byte(`{"data": {}}`),
+		"profileicon.json":   []byte(`{"data": {}}`),
+	}
+
+	if _, err := parseStaticData("15.1.1", files); err == nil {
+		t.Error("parseStaticData with an invalid item.json: want an error")
+	}
+}
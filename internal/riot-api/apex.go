@@ -0,0 +1,62 @@
+package riotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	methodChallengerLeague  = "league-v4.getChallengerLeague"
+	methodGrandmasterLeague = "league-v4.getGrandmasterLeague"
+	methodMasterLeague      = "league-v4.getMasterLeague"
+)
+
+// ApexTiers are the tiers with a single ladder per platform and queue, highest first.
+var ApexTiers = []string{"CHALLENGER", "GRANDMASTER", "MASTER"}
+
+// ApexLeague is the ladder of an apex tier (Master, Grandmaster or Challenger) of a queue, as returned by league-v4.
+type ApexLeague struct {
+	Tier    string            `json:"tier"`
+	Queue   string            `json:"queue"`
+	Entries []ApexLeagueEntry `json:"entries"`
+}
+
+// ApexLeagueEntry is a player of an ApexLeague.
+type ApexLeagueEntry struct {
+	PUUID        string `json:"puuid"`
+	LeaguePoints int    `json:"leaguePoints"`
+	Wins         int    `json:"wins"`
+	Losses       int    `json:"losses"`
+}
+
+// GetApexLeague fetch every player of an apex tier (CHALLENGER, GRANDMASTER or MASTER) in a ranked queue of a platform.
+func (c *Client) GetApexLeague(ctx context.Context, platform, tier, queueType string) (*ApexLeague, error) {
+	var method, path string
+	switch strings.ToUpper(tier) {
+	case "CHALLENGER":
+		method, path = methodChallengerLeague, "challengerleagues"
+	case "GRANDMASTER":
+		method, path = methodGrandmasterLeague, "grandmasterleagues"
+	case "MASTER":
+		method, path = methodMasterLeague, "masterleagues"
+	default:
+		return nil, fmt.Errorf("'%s' is not an apex tier", tier)
+	}
+
+	url := fmt.Sprintf("%s/lol/league/v4/%s/by-queue/%s", c.platformHost(c.platformOrDefault(platform)), path, queueType)
+
+	resp, err := c.makeRequest(ctx, method, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var league ApexLeague
+	if err := json.NewDecoder(resp.Body).Decode(&league); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &league, nil
+}
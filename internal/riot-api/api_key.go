@@ -0,0 +1,119 @@
+package riotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ErrInvalidAPIKey is returned without sending any request once Riot rejected the API key (see rejectsKey),
+// until a new key is set with SetAPIKey.
+var ErrInvalidAPIKey = errors.New("Riot API key is invalid or expired")
+
+// forbiddenKeyCheckInterval is how long a key accepted by lol-status-v4 after a 403 isn't checked again, some
+// endpoints answer 403 to keys that have no access to them.
+const forbiddenKeyCheckInterval = time.Minute
+
+// keyCheckContextKey marks the context of the request made by CheckAPIKey.
+type keyCheckContextKey struct{}
+
+// WithInvalidKeyHandler calls handler, with the error Riot answered, whenever the API key becomes invalid.
+// It is called once per invalid key, from the goroutine of the rejected request.
+func WithInvalidKeyHandler(handler func(err error)) ClientOption {
+	return func(c *Client) {
+		c.onInvalidKey = handler
+	}
+}
+
+// SetAPIKey replaces the API key used by the client and resumes requests if the previous key was invalid.
+func (c *Client) SetAPIKey(apiKey string) {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+
+	c.apiKey = apiKey
+	c.keyInvalid = false
+	c.keyAcceptedAt = time.Time{}
+}
+
+// CheckAPIKey sends a lol-status-v4 request of platform to check the current API key, and returns an error
+// wrapping ErrInvalidAPIKey when Riot rejects it. The key is flagged as invalid like on any other request,
+// but the invalid key handler isn't called: the caller is the one reporting the outcome of the check.
+func (c *Client) CheckAPIKey(ctx context.Context, platform string) error {
+	_, err := c.GetPlatformStatus(context.WithValue(ctx, keyCheckContextKey{}, true), platform)
+	if err != nil && c.KeyInvalid() {
+		return fmt.Errorf("%w: %v", ErrInvalidAPIKey, err)
+	}
+
+	return err
+}
+
+// APIKey returns the API key used by the client, whether Riot rejected it or not.
+func (c *Client) APIKey() string {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+
+	return c.apiKey
+}
+
+// checkForbiddenKey checks apiKey against lol-status-v4 of the default platform after another endpoint answered
+// 403: an expired development key is flagged as invalid right away, instead of failing every request until the
+// next status poll. A single check runs at a time, and a key the check accepted isn't checked again for
+// forbiddenKeyCheckInterval.
+func (c *Client) checkForbiddenKey(ctx context.Context, apiKey string) {
+	c.keyMu.Lock()
+	if c.apiKey != apiKey || c.keyInvalid || c.keyChecking || time.Since(c.keyAcceptedAt) < forbiddenKeyCheckInterval {
+		c.keyMu.Unlock()
+		return
+	}
+	c.keyChecking = true
+	c.keyMu.Unlock()
+
+	// a 403 of lol-status-v4 flags the key as invalid, see rejectsKey
+	_, err := c.GetPlatformStatus(ctx, c.region)
+
+	c.keyMu.Lock()
+	c.keyChecking = false
+	if err == nil && c.apiKey == apiKey {
+		c.keyAcceptedAt = time.Now()
+	}
+	c.keyMu.Unlock()
+}
+
+// KeyInvalid reports whether Riot rejected the current API key.
+func (c *Client) KeyInvalid() bool {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+
+	return c.keyInvalid
+}
+
+// currentAPIKey returns the API key to send, or ErrInvalidAPIKey if Riot already rejected it.
+func (c *Client) currentAPIKey() (string, error) {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+
+	if c.keyInvalid {
+		return "", ErrInvalidAPIKey
+	}
+
+	return c.apiKey, nil
+}
+
+// markKeyInvalid flags apiKey as rejected by Riot. A key replaced while the request was in flight isn't flagged.
+func (c *Client) markKeyInvalid(ctx context.Context, apiKey string, err error) {
+	c.keyMu.Lock()
+	if c.apiKey != apiKey || c.keyInvalid {
+		c.keyMu.Unlock()
+		return
+	}
+	c.keyInvalid = true
+	c.keyMu.Unlock()
+
+	log.Printf("Riot rejected the API key, requests are suspended until a new key is set: %v", err)
+
+	if c.onInvalidKey != nil && ctx.Value(keyCheckContextKey{}) == nil {
+		c.onInvalidKey(err)
+	}
+}
@@ -0,0 +1,72 @@
+package riotapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// forbiddenTransport answers 403 to every request but the lol-status-v4 ones, answered statusCode.
+type forbiddenTransport struct {
+	statusCode     int
+	statusRequests *int
+}
+
+func (ft forbiddenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	statusCode, body := http.StatusForbidden, `{"status": {"message": "Forbidden", "status_code": 403}}`
+	if strings.Contains(req.URL.Path, "/lol/status/v4/") {
+		*ft.statusRequests++
+		statusCode = ft.statusCode
+		if statusCode == http.StatusOK {
+			body = `{"id": "EUW1", "name": "EU West", "maintenances": [], "incidents": []}`
+		}
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestForbiddenKeyCheck(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCode       int
+		wantInvalid      bool
+		wantHandlerCalls int
+	}{
+		{name: "expired key", statusCode: http.StatusForbidden, wantInvalid: true, wantHandlerCalls: 1},
+		{name: "endpoint the key can't call", statusCode: http.StatusOK, wantInvalid: false, wantHandlerCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var statusRequests, handlerCalls int
+			client := NewClient("RGAPI-test", "euw1",
+				WithTransport(forbiddenTransport{statusCode: tt.statusCode, statusRequests: &statusRequests}),
+				WithInvalidKeyHandler(func(err error) { handlerCalls++ }),
+			)
+
+			for range 2 {
+				if _, err := client.GetSummonerByPUUID(context.Background(), "euw1", "puuid-blue-middle"); err == nil {
+					t.Fatal("GetSummonerByPUUID: want an error")
+				}
+			}
+
+			if got := client.KeyInvalid(); got != tt.wantInvalid {
+				t.Errorf("KeyInvalid() = %t, want %t", got, tt.wantInvalid)
+			}
+			if handlerCalls != tt.wantHandlerCalls {
+				t.Errorf("invalid key handler called %d times, want %d", handlerCalls, tt.wantHandlerCalls)
+			}
+			// the second 403 is answered without a request once the key is invalid, or without a check once it was accepted
+			if statusRequests != 1 {
+				t.Errorf("key checked %d times, want 1", statusRequests)
+			}
+		})
+	}
+}
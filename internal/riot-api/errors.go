@@ -1,6 +1,7 @@
 package riotapi
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -18,13 +19,22 @@ func (e *RiotAPIError) Error() string {
 	return fmt.Sprintf("Riot API error (status %d): %s", e.StatusCode, e.Message)
 }
 
+// asRiotAPIError returns the RiotAPIError wrapped in err, if any.
+func asRiotAPIError(err error) (*RiotAPIError, bool) {
+	var apiErr *RiotAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
 // IsRateLimitError checks if the error is a rate limit error based on Riot API documentation
 func IsRateLimitError(err error) bool {
 	if err == nil {
 		return false
 	}
 
-	if apiErr, ok := err.(*RiotAPIError); ok {
+	if apiErr, ok := asRiotAPIError(err); ok {
 		// Check for 429 status code
 		if apiErr.StatusCode == http.StatusTooManyRequests {
 			// Check for X-Rate-Limit-Type header
@@ -38,3 +48,59 @@ func IsRateLimitError(err error) bool {
 
 	return strings.Contains(strings.ToLower(err.Error()), "rate limit")
 }
+
+// IsNotFoundError checks if the error is a 404 returned by the Riot API (unknown account, match, summoner...)
+func IsNotFoundError(err error) bool {
+	apiErr, ok := asRiotAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsInvalidKeyError checks if the error is ErrInvalidAPIKey, or a 401 returned by the Riot API
+// meaning the API key is missing or invalid.
+func IsInvalidKeyError(err error) bool {
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return true
+	}
+
+	apiErr, ok := asRiotAPIError(err)
+	return ok && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// keyCheckMethods are the methods every API key can call. Riot also answers 403 to a key calling an endpoint
+// it has no access to, only a 403 on one of these means the key itself is rejected (e.g. an expired dev key).
+// A 403 on another method is checked against lol-status-v4, see checkForbiddenKey.
+var keyCheckMethods = map[string]bool{
+	methodAccountByRiotID: true,
+	methodAccountByPUUID:  true,
+	methodPlatformStatus:  true,
+}
+
+// rejectsKey reports whether Riot answered apiErr to a request of method because of the API key itself.
+func rejectsKey(method string, apiErr *RiotAPIError) bool {
+	return apiErr.StatusCode == http.StatusUnauthorized || (apiErr.StatusCode == http.StatusForbidden && keyCheckMethods[method])
+}
+
+// IsServerError checks if the error is a 5xx returned by the Riot API (outage, maintenance...)
+func IsServerError(err error) bool {
+	apiErr, ok := asRiotAPIError(err)
+	return ok && apiErr.StatusCode >= http.StatusInternalServerError
+}
+
+// IsRetryableError checks if a request that failed with err may succeed later:
+// rate limits, Riot outages and errors that did not come from a Riot response (network errors, timeouts).
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrInvalidAPIKey) {
+		return false
+	}
+
+	apiErr, ok := asRiotAPIError(err)
+	if !ok {
+		return true
+	}
+
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+}
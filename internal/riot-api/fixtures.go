@@ -0,0 +1,163 @@
+package riotapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// maxFixtureNameLength caps the readable part of fixture file names, the hash suffix keeps them unique.
+const maxFixtureNameLength = 120
+
+// fixtureHeaders are the response headers kept in fixtures, the ones the client reads.
+var fixtureHeaders = []string{"Content-Type", "Retry-After", "X-Rate-Limit-Type", "X-App-Rate-Limit", "X-App-Rate-Limit-Count", "X-Method-Rate-Limit", "X-Method-Rate-Limit-Count"}
+
+// volatileFixtureParams are query parameters left out of fixture names, they change at every run: startTime is
+// derived from the time of the last stored match.
+var volatileFixtureParams = []string{"startTime"}
+
+var unsafeFixtureChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fixture is a recorded response of the Riot API, stored as JSON.
+type fixture struct {
+	URL        string              `json:"url"`
+	StatusCode int                 `json:"statusCode"`
+	Header     map[string][]string `json:"header"`
+	Body       string              `json:"body"`
+}
+
+// RecordingTransport sends requests through Next (http.DefaultTransport when nil) and writes every response
+// to a fixture file in Dir, to be served back later by a ReplayTransport. The API key is never written, and neither
+// are rate limited or unauthorized responses, which depend on the moment and the key of the recording.
+type RecordingTransport struct {
+	Dir  string
+	Next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isFixtureStatus(resp.StatusCode) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	f := fixture{
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     make(map[string][]string),
+		Body:       string(body),
+	}
+	for _, name := range fixtureHeaders {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			f.Header[name] = values
+		}
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding fixture: %w", err)
+	}
+
+	if err := os.MkdirAll(t.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating fixtures directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(t.Dir, fixtureName(req)), data, 0o644); err != nil {
+		return nil, fmt.Errorf("error writing fixture: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ReplayTransport serves the responses recorded by a RecordingTransport in Dir, without any network access.
+// A request that wasn't recorded fails with an error naming the missing fixture, like one whose fixture holds
+// a rate limited or unauthorized response.
+type ReplayTransport struct {
+	Dir string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := filepath.Join(t.Dir, fixtureName(req))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no fixture recorded for %s (%s): %w", req.URL, path, err)
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("error decoding fixture %s: %w", path, err)
+	}
+	if !isFixtureStatus(f.StatusCode) {
+		return nil, fmt.Errorf("fixture %s holds a %d response, record %s again", path, f.StatusCode, req.URL)
+	}
+
+	header := make(http.Header)
+	for name, values := range f.Header {
+		for _, value := range values {
+			header.Add(name, value)
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.StatusCode, http.StatusText(f.StatusCode)),
+		StatusCode:    f.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(f.Body))),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+// isFixtureStatus reports whether a response of this status is kept in fixtures. 429 and 401 responses only tell
+// how busy the key was, or whether it was valid, while recording.
+func isFixtureStatus(statusCode int) bool {
+	return statusCode != http.StatusTooManyRequests && statusCode != http.StatusUnauthorized
+}
+
+// fixtureName returns the file name of the fixture of a request: a readable version of its host, path and query,
+// followed by a hash of its method and URL. volatileFixtureParams are left out of both.
+func fixtureName(req *http.Request) string {
+	query := req.URL.Query()
+	for _, param := range volatileFixtureParams {
+		query.Del(param)
+	}
+
+	key := *req.URL
+	key.RawQuery = query.Encode()
+
+	name := unsafeFixtureChars.ReplaceAllString(key.Host+key.Path+"_"+key.RawQuery, "_")
+	if len(name) > maxFixtureNameLength {
+		name = name[:maxFixtureNameLength]
+	}
+
+	hash := sha256.Sum256([]byte(req.Method + " " + key.String()))
+
+	return fmt.Sprintf("%s_%x.json", name, hash[:6])
+}
@@ -0,0 +1,230 @@
+package riotapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// replayFixturesDir holds the responses recorded for a tracked summoner (puuid-blue-middle) who played
+// EUW1_7000000001 and EUW1_7000000002 in ranked solo/duo after EUW1_7000000000.
+const replayFixturesDir = "testdata/riot-fixtures"
+
+// newReplayClient returns a client served by the recorded fixtures only.
+func newReplayClient() *Client {
+	return NewClient("RGAPI-test", "euw1", WithTransport(&ReplayTransport{Dir: replayFixturesDir}))
+}
+
+// stubTransport answers every request with the same status and body.
+type stubTransport struct {
+	statusCode int
+	body       string
+}
+
+func (st stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: st.statusCode,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(st.body)),
+		Request:    req,
+	}, nil
+}
+
+func newRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest(%q): %v", url, err)
+	}
+
+	return req
+}
+
+func TestFixtureName(t *testing.T) {
+	const idsURL = "https://europe.api.riotgames.com/lol/match/v5/matches/by-puuid/abc/ids?queue=420&start=0&count=20"
+
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{
+			name: "startTime is ignored",
+			a:    idsURL + "&startTime=1760590000",
+			b:    idsURL + "&startTime=1760600000",
+			same: true,
+		},
+		{
+			name: "startTime or not",
+			a:    idsURL,
+			b:    idsURL + "&startTime=1760590000",
+			same: true,
+		},
+		{
+			name: "query order is ignored",
+			a:    "https://europe.api.riotgames.com/lol/match/v5/matches/by-puuid/abc/ids?count=20&queue=420&start=0",
+			b:    idsURL,
+			same: true,
+		},
+		{
+			name: "other queue",
+			a:    idsURL,
+			b:    strings.Replace(idsURL, "queue=420", "queue=440", 1),
+			same: false,
+		},
+		{
+			name: "other page",
+			a:    idsURL,
+			b:    strings.Replace(idsURL, "start=0", "start=20", 1),
+			same: false,
+		},
+		{
+			name: "other host",
+			a:    "https://euw1.api.riotgames.com/lol/summoner/v4/summoners/by-puuid/abc",
+			b:    "https://na1.api.riotgames.com/lol/summoner/v4/summoners/by-puuid/abc",
+			same: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := fixtureName(newRequest(t, tt.a)), fixtureName(newRequest(t, tt.b))
+			if (a == b) != tt.same {
+				t.Errorf("fixtureName(%q) = %q, fixtureName(%q) = %q, want same = %t", tt.a, a, tt.b, b, tt.same)
+			}
+		})
+	}
+}
+
+func TestRecordingTransport(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		recorded   bool
+	}{
+		{statusCode: http.StatusOK, recorded: true},
+		{statusCode: http.StatusNotFound, recorded: true},
+		{statusCode: http.StatusServiceUnavailable, recorded: true},
+		{statusCode: http.StatusTooManyRequests, recorded: false},
+		{statusCode: http.StatusUnauthorized, recorded: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			dir := t.TempDir()
+			transport := &RecordingTransport{Dir: dir, Next: stubTransport{statusCode: tt.statusCode, body: `{"ok":true}`}}
+
+			req := newRequest(t, "https://euw1.api.riotgames.com/lol/status/v4/platform-data")
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.statusCode || string(body) != `{"ok":true}` {
+				t.Errorf("RoundTrip = %d %q, want %d with the response body", resp.StatusCode, body, tt.statusCode)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, fixtureName(req)))
+			if recorded := err == nil; recorded != tt.recorded {
+				t.Errorf("fixture recorded = %t, want %t", recorded, tt.recorded)
+			}
+		})
+	}
+}
+
+func TestReplayTransport(t *testing.T) {
+	dir := t.TempDir()
+	const url = "https://euw1.api.riotgames.com/lol/status/v4/platform-data"
+
+	// a fixture written before rate limited responses were left out
+	fixture := []byte(`{"url": "` + url + `", "statusCode": 429, "header": {"Retry-After": ["10"]}, "body": ""}`)
+	if err := os.WriteFile(filepath.Join(dir, fixtureName(newRequest(t, url))), fixture, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &ReplayTransport{Dir: dir}
+
+	if _, err := transport.RoundTrip(newRequest(t, url)); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("replaying a 429 fixture: err = %v, want an error naming the status", err)
+	}
+	if _, err := transport.RoundTrip(newRequest(t, url+"?missing")); err == nil || !strings.Contains(err.Error(), "no fixture") {
+		t.Errorf("replaying a missing fixture: err = %v, want a missing fixture error", err)
+	}
+
+	// a recorded response is served back as is
+	recorder := &RecordingTransport{Dir: dir, Next: stubTransport{statusCode: http.StatusOK, body: `[1,2]`}}
+	if _, err := recorder.RoundTrip(newRequest(t, url+"?recorded")); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := transport.RoundTrip(newRequest(t, url+"?recorded"))
+	if err != nil {
+		t.Fatalf("replaying a recorded fixture: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, []byte(`[1,2]`)) {
+		t.Errorf("replayed %d %q, want 200 [1,2]", resp.StatusCode, body)
+	}
+}
+
+// TestReplayMatchTracking follows the requests of a check of a summoner who played two matches since the last
+// stored one, with a startTime that differs from the recording.
+func TestReplayMatchTracking(t *testing.T) {
+	client := newReplayClient()
+	ctx := context.Background()
+	const puuid = "puuid-blue-middle"
+
+	summoner, err := client.GetSummonerByPUUID(ctx, "euw1", puuid)
+	if err != nil {
+		t.Fatalf("GetSummonerByPUUID: %v", err)
+	}
+	if summoner.RevisionDate != 1760603800000 || summoner.Region != "euw1" {
+		t.Errorf("summoner = %+v, want revision date 1760603800000 on euw1", summoner)
+	}
+
+	entries, err := client.GetLeagueEntries(ctx, "euw1", puuid)
+	if err != nil {
+		t.Fatalf("GetLeagueEntries: %v", err)
+	}
+	solo := FindLeagueEntry(entries, QueueTypeRankedSolo)
+	if solo.Tier != "EMERALD" || solo.Rank != "II" || solo.LeaguePoints != 54 {
+		t.Errorf("solo/duo entry = %+v, want EMERALD II 54 LP", solo)
+	}
+	if flex := FindLeagueEntry(entries, QueueTypeRankedFlex); flex.Tier != "UNRANKED" {
+		t.Errorf("flex entry = %+v, want UNRANKED", flex)
+	}
+
+	matches, more, err := client.GetNewMatchesForSummoner(ctx, "euw1", puuid, 420, "EUW1_7000000000", 1760595000000)
+	if err != nil {
+		t.Fatalf("GetNewMatchesForSummoner: %v", err)
+	}
+	if more || len(matches) != 2 {
+		t.Fatalf("GetNewMatchesForSummoner = %d matches, more = %t, want 2 matches and no more", len(matches), more)
+	}
+
+	tests := []struct {
+		matchID  string
+		win      bool
+		champion string
+		kda      [3]int
+	}{
+		{matchID: "EUW1_7000000001", win: true, champion: "Ahri", kda: [3]int{7, 1, 6}},
+		{matchID: "EUW1_7000000002", win: false, champion: "Ahri", kda: [3]int{7, 1, 6}},
+	}
+	for idx, tt := range tests {
+		match := matches[idx]
+		if match.MatchID != tt.matchID || match.Win != tt.win || match.ChampionName != tt.champion {
+			t.Errorf("match %d = %s win %t on %s, want %s win %t on %s", idx, match.MatchID, match.Win, match.ChampionName, tt.matchID, tt.win, tt.champion)
+		}
+		if kda := [3]int{match.Kills, match.Deaths, match.Assists}; kda != tt.kda {
+			t.Errorf("match %s KDA = %v, want %v", match.MatchID, kda, tt.kda)
+		}
+		if len(match.Participants) != 10 {
+			t.Errorf("match %s has %d participants, want 10", match.MatchID, len(match.Participants))
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package riotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	methodChampionMasteriesByPUUID    = "champion-mastery-v4.getAllChampionMasteriesByPUUID"
+	methodTopChampionMasteriesByPUUID = "champion-mastery-v4.getTopChampionMasteriesByPUUID"
+)
+
+// ChampionMastery is the mastery of a player on a champion, as returned by champion-mastery-v4.
+type ChampionMastery struct {
+	ChampionID     int   `json:"championId"`
+	ChampionLevel  int   `json:"championLevel"`
+	ChampionPoints int   `json:"championPoints"`
+	LastPlayTime   int64 `json:"lastPlayTime"`
+}
+
+// GetChampionMasteries fetch the mastery of a summoner on every champion they played, highest points first.
+func (c *Client) GetChampionMasteries(ctx context.Context, platform, puuid string) ([]ChampionMastery, error) {
+	url := fmt.Sprintf("%s/lol/champion-mastery/v4/champion-masteries/by-puuid/%s", c.platformHost(c.platformOrDefault(platform)), puuid)
+
+	return c.getChampionMasteries(ctx, methodChampionMasteriesByPUUID, url)
+}
+
+// GetTopChampionMasteries fetch the count champions a summoner has the most mastery points on.
+func (c *Client) GetTopChampionMasteries(ctx context.Context, platform, puuid string, count int) ([]ChampionMastery, error) {
+	url := fmt.Sprintf("%s/lol/champion-mastery/v4/champion-masteries/by-puuid/%s/top?count=%d", c.platformHost(c.platformOrDefault(platform)), puuid, count)
+
+	return c.getChampionMasteries(ctx, methodTopChampionMasteriesByPUUID, url)
+}
+
+func (c *Client) getChampionMasteries(ctx context.Context, method, url string) ([]ChampionMastery, error) {
+	resp, err := c.makeRequest(ctx, method, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var masteries []ChampionMastery
+	if err := json.NewDecoder(resp.Body).Decode(&masteries); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return masteries, nil
+}
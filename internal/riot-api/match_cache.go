@@ -0,0 +1,108 @@
+package riotapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// matchCacheTTL is how long a fetched match or timeline is kept, long enough for every tracked player of a game
+	// to be checked in the same tracking pass.
+	matchCacheTTL = 15 * time.Minute
+	// matchCacheSize caps the matches kept, the oldest are dropped first.
+	matchCacheSize = 256
+	// timelineCacheSize caps the timelines kept, they are much larger than matches.
+	timelineCacheSize = 64
+	// matchFetchTimeout bounds a fetch shared by the lookups of a match, whatever their own deadline.
+	matchFetchTimeout = 2 * time.Minute
+)
+
+// matchCache keeps the matches, or their timelines, fetched recently, so a match played by several tracked summoners
+// is fetched once. Concurrent lookups of a match being fetched wait for that fetch instead of sending their own request.
+type matchCache[T any] struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*cachedMatch[T]
+}
+
+// cachedMatch is a match fetched, or being fetched until ready is closed.
+type cachedMatch[T any] struct {
+	ready     chan struct{}
+	value     T
+	err       error
+	fetchedAt time.Time
+}
+
+// newMatchCache returns a cache keeping up to size matches.
+func newMatchCache[T any](size int) *matchCache[T] {
+	return &matchCache[T]{size: size, entries: make(map[string]*cachedMatch[T])}
+}
+
+// get returns the match of matchID, calling fetch unless it was fetched less than matchCacheTTL ago.
+// The fetch is shared by every lookup of the match, so it doesn't depend on the context of the one that started it:
+// it runs on a context without its cancellation, bounded by matchFetchTimeout. Each lookup only waits until its own
+// ctx is done. A failed fetch isn't kept, the next lookup tries again.
+func (mc *matchCache[T]) get(ctx context.Context, matchID string, fetch func(ctx context.Context) (T, error)) (T, error) {
+	mc.mu.Lock()
+	entry, ok := mc.entries[matchID]
+	if !ok || (!entry.fetchedAt.IsZero() && time.Since(entry.fetchedAt) >= matchCacheTTL) {
+		entry = &cachedMatch[T]{ready: make(chan struct{})}
+		mc.entries[matchID] = entry
+		go mc.fetch(context.WithoutCancel(ctx), matchID, entry, fetch)
+	}
+	mc.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.value, entry.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// fetch fills entry with the match of matchID and wakes up the lookups waiting for it.
+func (mc *matchCache[T]) fetch(ctx context.Context, matchID string, entry *cachedMatch[T], fetch func(ctx context.Context) (T, error)) {
+	ctx, cancel := context.WithTimeout(ctx, matchFetchTimeout)
+	defer cancel()
+
+	value, err := fetch(ctx)
+
+	mc.mu.Lock()
+	entry.value, entry.err = value, err
+	if err != nil {
+		// a lookup may have replaced the entry since
+		if mc.entries[matchID] == entry {
+			delete(mc.entries, matchID)
+		}
+	} else {
+		entry.fetchedAt = time.Now()
+		mc.evict()
+	}
+	mc.mu.Unlock()
+	close(entry.ready)
+}
+
+// evict drops the expired matches, then the oldest ones past the size of the cache. mc.mu must be held.
+func (mc *matchCache[T]) evict() {
+	for matchID, entry := range mc.entries {
+		if !entry.fetchedAt.IsZero() && time.Since(entry.fetchedAt) >= matchCacheTTL {
+			delete(mc.entries, matchID)
+		}
+	}
+
+	for len(mc.entries) > mc.size {
+		var oldestID string
+		var oldest time.Time
+		for matchID, entry := range mc.entries {
+			if !entry.fetchedAt.IsZero() && (oldestID == "" || entry.fetchedAt.Before(oldest)) {
+				oldestID, oldest = matchID, entry.fetchedAt
+			}
+		}
+		if oldestID == "" {
+			return
+		}
+		delete(mc.entries, oldestID)
+	}
+}
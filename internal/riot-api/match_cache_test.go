@@ -0,0 +1,84 @@
+package riotapi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMatchCacheCanceledLookup(t *testing.T) {
+	mc := newMatchCache[string](matchCacheSize)
+	release := make(chan struct{})
+	var fetches atomic.Int32
+
+	fetch := func(ctx context.Context) (string, error) {
+		fetches.Add(1)
+		select {
+		case <-release:
+			return "match", nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	// the lookup starting the fetch gives up before it is over
+	ctx, cancel := context.WithCancel(context.Background())
+	firstDone := make(chan error)
+	go func() {
+		_, err := mc.get(ctx, "EUW1_7000000001", fetch)
+		firstDone <- err
+	}()
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("get() of the canceled lookup = %v, want %v", err, context.Canceled)
+	}
+
+	secondDone := make(chan error)
+	go func() {
+		match, err := mc.get(context.Background(), "EUW1_7000000001", fetch)
+		if err == nil && match != "match" {
+			err = errors.New("unexpected match " + match)
+		}
+		secondDone <- err
+	}()
+	close(release)
+
+	if err := <-secondDone; err != nil {
+		t.Errorf("get() of the other lookup = %v, want the match of the shared fetch", err)
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("match fetched %d times, want 1", n)
+	}
+}
+
+func TestMatchCacheFailedFetch(t *testing.T) {
+	mc := newMatchCache[string](matchCacheSize)
+	var fetches int
+
+	failing := func(ctx context.Context) (string, error) {
+		fetches++
+		return "", errors.New("match-v5 unavailable")
+	}
+	if _, err := mc.get(context.Background(), "EUW1_7000000001", failing); err == nil {
+		t.Fatal("get() with a failing fetch: want an error")
+	}
+
+	working := func(ctx context.Context) (string, error) {
+		fetches++
+		return "match", nil
+	}
+	for range 2 {
+		if match, err := mc.get(context.Background(), "EUW1_7000000001", working); err != nil || match != "match" {
+			t.Fatalf("get() after a failed fetch = %q, %v, want the match", match, err)
+		}
+	}
+
+	if fetches != 2 {
+		t.Errorf("match fetched %d times, want 2: the failure is retried, the match is kept", fetches)
+	}
+}
@@ -0,0 +1,134 @@
+package riotapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Queue ids of the games the bot announces.
+// https://static.developer.riotgames.com/docs/lol/queues.json
+const (
+	QueueNormalDraft = 400
+	QueueRankedSolo  = 420
+	QueueNormalBlind = 430
+	QueueRankedFlex  = 440
+	QueueARAM        = 450
+	QueueQuickplay   = 490
+	QueueArena       = 1700
+)
+
+// League-v4 queue types of the ranked queues.
+const (
+	QueueTypeRankedSolo = "RANKED_SOLO_5x5"
+	QueueTypeRankedFlex = "RANKED_FLEX_SR"
+)
+
+// DefaultTrackedQueues are the queues tracked by a guild that didn't choose any.
+var DefaultTrackedQueues = []int{QueueRankedSolo}
+
+// queue describes a queue the bot can track.
+type queue struct {
+	// name is the name shown in announcements.
+	name string
+	// alias is the short name used in commands.
+	alias string
+	// leagueQueueType is the league-v4 queue type of ranked queues, empty for unranked ones.
+	leagueQueueType string
+	// lanes is set for Summoner's Rift queues, whose players face an opponent in their lane.
+	lanes bool
+}
+
+var queues = map[int]queue{
+	QueueRankedSolo:  {name: "Ranked Solo/Duo", alias: "solo", leagueQueueType: QueueTypeRankedSolo, lanes: true},
+	QueueRankedFlex:  {name: "Ranked Flex", alias: "flex", leagueQueueType: QueueTypeRankedFlex, lanes: true},
+	QueueNormalDraft: {name: "Normal Draft", alias: "draft", lanes: true},
+	QueueNormalBlind: {name: "Normal Blind", alias: "blind", lanes: true},
+	QueueQuickplay:   {name: "Quickplay", alias: "quickplay", lanes: true},
+	QueueARAM:        {name: "ARAM", alias: "aram"},
+	QueueArena:       {name: "Arena", alias: "arena"},
+}
+
+// QueueName returns the display name of a queue id.
+func QueueName(queueID int) string {
+	if q, ok := queues[queueID]; ok {
+		return q.name
+	}
+
+	return "Custom"
+}
+
+// QueueAlias returns the short name of a queue id used in commands (e.g. "flex").
+func QueueAlias(queueID int) string {
+	if q, ok := queues[queueID]; ok {
+		return q.alias
+	}
+
+	return fmt.Sprintf("%d", queueID)
+}
+
+// QueueTypeName returns the display name of a league-v4 queue type (e.g. "Ranked Flex" for RANKED_FLEX_SR).
+func QueueTypeName(queueType string) string {
+	for _, q := range queues {
+		if q.leagueQueueType == queueType {
+			return q.name
+		}
+	}
+
+	return queueType
+}
+
+// LeagueQueueType returns the league-v4 queue type of a ranked queue id, or an empty string for unranked queues.
+func LeagueQueueType(queueID int) string {
+	return queues[queueID].leagueQueueType
+}
+
+// IsRankedQueue reports whether games of a queue change the LP of their players.
+func IsRankedQueue(queueID int) bool {
+	return LeagueQueueType(queueID) != ""
+}
+
+// HasLanes reports whether games of a queue are played in lanes, which laning stats are only meaningful for.
+func HasLanes(queueID int) bool {
+	return queues[queueID].lanes
+}
+
+// ParseQueue returns the queue id of a queue short name (e.g. "flex" -> 440).
+func ParseQueue(alias string) (int, error) {
+	alias = strings.ToLower(strings.TrimSpace(alias))
+	for id, q := range queues {
+		if q.alias == alias {
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown queue '%s', expected one of %s", alias, strings.Join(QueueAliases(), ", "))
+}
+
+// QueueAliases returns the short name of every trackable queue, ranked queues first.
+func QueueAliases() []string {
+	ids := TrackableQueues()
+	aliases := make([]string, 0, len(ids))
+	for _, id := range ids {
+		aliases = append(aliases, queues[id].alias)
+	}
+
+	return aliases
+}
+
+// TrackableQueues returns the id of every queue the bot can track, ranked queues first.
+func TrackableQueues() []int {
+	ids := make([]int, 0, len(queues))
+	for id := range queues {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if IsRankedQueue(ids[i]) != IsRankedQueue(ids[j]) {
+			return IsRankedQueue(ids[i])
+		}
+		return ids[i] < ids[j]
+	})
+
+	return ids
+}
@@ -2,25 +2,252 @@ package riotapi
 
 import (
 	"context"
-
-	"golang.org/x/time/rate"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
+// defaultAppRateLimit is the app rate limit of a development key (20 requests every 1s, 100 every 2min).
+// It is used for a routing host until Riot returns the real limits in the X-App-Rate-Limit header.
+const defaultAppRateLimit = "20:1,100:120"
+
+// RateLimiter paces requests to the Riot API using the rate limits returned by Riot.
+// App limits (X-App-Rate-Limit) are tracked per routing host (euw1, europe...),
+// method limits (X-Method-Rate-Limit) per routing host and endpoint.
+// Each limit is made of several windows (e.g. 20 per 1s and 100 per 120s), all of them must allow a request.
 type RateLimiter struct {
-	limiter *rate.Limiter
+	mu               sync.Mutex
+	app              map[string]*rateBucket
+	methods          map[string]*rateBucket
+	defaultAppLimits string
 }
 
-// NewRateLimiter creates a new RateLimiter with specified rate and burst size.
-// requestsPerSecond: The number of requests allowed per second.
-// burstSize: The maximum number of requests allowed to be made at once.
-func NewRateLimiter(requestsPerSecond float64, burstSize int) *RateLimiter {
+// rateBucket holds every window of a single rate limit.
+type rateBucket struct {
+	windows      []*rateWindow
+	blockedUntil time.Time
+}
+
+// rateWindow counts requests made during a fixed window of time.
+type rateWindow struct {
+	limit    int
+	duration time.Duration
+	count    int
+	resetAt  time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter.
+// defaultAppLimits uses the X-App-Rate-Limit header format ("20:1,100:120") and applies to every routing host
+// until Riot returns its actual limits.
+func NewRateLimiter(defaultAppLimits string) *RateLimiter {
 	return &RateLimiter{
-		limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), burstSize),
+		app:              make(map[string]*rateBucket),
+		methods:          make(map[string]*rateBucket),
+		defaultAppLimits: defaultAppLimits,
+	}
+}
+
+// Wait blocks until a request to the given method on the given host can be made
+// without exceeding the app or method rate limits, then counts the request.
+// It returns the context error if ctx is done before the request can be made.
+func (rl *RateLimiter) Wait(ctx context.Context, host, method string) error {
+	for {
+		rl.mu.Lock()
+		now := time.Now()
+		appBucket := rl.appBucket(host, now)
+		methodBucket := rl.methodBucket(host, method)
+
+		wait := max(appBucket.waitTime(now), methodBucket.waitTime(now))
+		if wait == 0 {
+			appBucket.take(now)
+			methodBucket.take(now)
+			rl.mu.Unlock()
+			return nil
+		}
+		rl.mu.Unlock()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Update synchronizes the limits and counts of a host and method with the rate limit headers of a response.
+func (rl *RateLimiter) Update(host, method string, headers http.Header) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+
+	if limits := headers.Get("X-App-Rate-Limit"); limits != "" {
+		rl.appBucket(host, now).setLimits(limits, headers.Get("X-App-Rate-Limit-Count"), now)
+	}
+
+	if limits := headers.Get("X-Method-Rate-Limit"); limits != "" {
+		rl.methodBucket(host, method).setLimits(limits, headers.Get("X-Method-Rate-Limit-Count"), now)
+	}
+}
+
+// Backoff blocks every request of the limit that was exceeded for retryAfter.
+// limitType is the X-Rate-Limit-Type header of a 429 response ("application", "method" or "service").
+func (rl *RateLimiter) Backoff(host, method, limitType string, retryAfter time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	bucket := rl.methodBucket(host, method)
+	if limitType == "application" {
+		bucket = rl.appBucket(host, now)
+	}
+
+	if until := now.Add(retryAfter); until.After(bucket.blockedUntil) {
+		bucket.blockedUntil = until
+	}
+}
+
+// RequestsPerSecond returns the sustained request rate allowed by the app limit of a host: the rate of its most
+// restrictive window (e.g. 100 every 120s for a development key, not 20 every 1s).
+func (rl *RateLimiter) RequestsPerSecond(host string) float64 {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rate := math.Inf(1)
+	for _, w := range rl.appBucket(host, time.Now()).windows {
+		rate = math.Min(rate, float64(w.limit)/w.duration.Seconds())
+	}
+
+	return rate
+}
+
+// appBucket returns the app bucket of a host, creating it with the default app limits if needed.
+// rl.mu must be held.
+func (rl *RateLimiter) appBucket(host string, now time.Time) *rateBucket {
+	bucket, ok := rl.app[host]
+	if !ok {
+		bucket = &rateBucket{}
+		bucket.setLimits(rl.defaultAppLimits, "", now)
+		rl.app[host] = bucket
+	}
+
+	return bucket
+}
+
+// methodBucket returns the bucket of a method on a host. It has no window until Riot returns the method limits.
+// rl.mu must be held.
+func (rl *RateLimiter) methodBucket(host, method string) *rateBucket {
+	key := host + " " + method
+	bucket, ok := rl.methods[key]
+	if !ok {
+		bucket = &rateBucket{}
+		rl.methods[key] = bucket
 	}
+
+	return bucket
 }
 
-// Wait blocks until a request can be made without exceeding the rate limit.
-// It uses a background context, which means it will wait indefinitely if necessary.
-func (rl *RateLimiter) Wait() {
-	rl.limiter.Wait(context.Background())
+// waitTime returns how long to wait before a request fits in every window of the bucket, 0 if it fits now.
+func (b *rateBucket) waitTime(now time.Time) time.Duration {
+	var wait time.Duration
+	if now.Before(b.blockedUntil) {
+		wait = b.blockedUntil.Sub(now)
+	}
+
+	for _, w := range b.windows {
+		if !w.resetAt.IsZero() && !now.Before(w.resetAt) {
+			w.count = 0
+			w.resetAt = time.Time{}
+		}
+
+		if w.count >= w.limit && w.resetAt.Sub(now) > wait {
+			wait = w.resetAt.Sub(now)
+		}
+	}
+
+	return wait
+}
+
+// take counts a request in every window of the bucket, starting windows that are not running yet.
+func (b *rateBucket) take(now time.Time) {
+	for _, w := range b.windows {
+		if w.resetAt.IsZero() {
+			w.resetAt = now.Add(w.duration)
+			w.count = 0
+		}
+		w.count++
+	}
+}
+
+// setLimits replaces the windows of the bucket with the ones described by a rate limit header
+// and its count header. Counts already tracked locally are kept when higher than Riot's.
+func (b *rateBucket) setLimits(limitsHeader, countsHeader string, now time.Time) {
+	limits := parseRateLimitHeader(limitsHeader)
+	counts := parseRateLimitHeader(countsHeader)
+
+	existing := make(map[time.Duration]*rateWindow, len(b.windows))
+	for _, w := range b.windows {
+		existing[w.duration] = w
+	}
+
+	windows := make([]*rateWindow, 0, len(limits))
+	for duration, limit := range limits {
+		w, ok := existing[duration]
+		if !ok {
+			w = &rateWindow{duration: duration}
+		}
+		w.limit = limit
+
+		if count := counts[duration]; count > w.count {
+			w.count = count
+			if w.resetAt.IsZero() {
+				w.resetAt = now.Add(duration)
+			}
+		}
+
+		windows = append(windows, w)
+	}
+
+	b.windows = windows
+}
+
+// sleepContext pauses for d, or until ctx is done in which case it returns the context error.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRateLimitHeader parses a rate limit header ("20:1,100:120") into a map of window duration to value.
+// Malformed entries are ignored.
+func parseRateLimitHeader(header string) map[time.Duration]int {
+	result := make(map[time.Duration]int)
+
+	for _, part := range strings.Split(header, ",") {
+		value, seconds, found := strings.Cut(strings.TrimSpace(part), ":")
+		if !found {
+			continue
+		}
+
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+
+		s, err := strconv.Atoi(seconds)
+		if err != nil || s <= 0 {
+			continue
+		}
+
+		result[time.Duration(s)*time.Second] = v
+	}
+
+	return result
 }
@@ -0,0 +1,198 @@
+package riotapi
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   map[time.Duration]int
+	}{
+		{
+			name:   "development key",
+			header: "20:1,100:120",
+			want:   map[time.Duration]int{time.Second: 20, 2 * time.Minute: 100},
+		},
+		{
+			name:   "counts",
+			header: "3:1,57:120",
+			want:   map[time.Duration]int{time.Second: 3, 2 * time.Minute: 57},
+		},
+		{
+			name:   "spaces around entries",
+			header: " 500:10 , 30000:600 ",
+			want:   map[time.Duration]int{10 * time.Second: 500, 10 * time.Minute: 30000},
+		},
+		{
+			name:   "malformed entries are skipped",
+			header: "20:1,abc:120,100,50:x,10:0,5:-1",
+			want:   map[time.Duration]int{time.Second: 20},
+		},
+		{
+			name:   "empty",
+			header: "",
+			want:   map[time.Duration]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseRateLimitHeader(tt.header)
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseRateLimitHeader(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+// pendingWait returns how long the next request to a method on a host would wait.
+func pendingWait(rl *RateLimiter, host, method string) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	return max(rl.appBucket(host, now).waitTime(now), rl.methodBucket(host, method).waitTime(now))
+}
+
+func TestRateLimiterAppLimit(t *testing.T) {
+	rl := NewRateLimiter("2:10")
+	for range 2 {
+		if err := rl.Wait(context.Background(), "euw1", methodSummonerByPUUID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if wait := pendingWait(rl, "euw1", methodLeagueEntriesByPUUID); wait <= 9*time.Second || wait > 10*time.Second {
+		t.Errorf("wait once the app limit is reached = %s, want the rest of the 10s window", wait)
+	}
+	if wait := pendingWait(rl, "na1", methodSummonerByPUUID); wait != 0 {
+		t.Errorf("wait on another host = %s, want 0", wait)
+	}
+}
+
+func TestRateLimiterMethodLimit(t *testing.T) {
+	rl := NewRateLimiter("100:10")
+	rl.Update("europe", methodMatchByID, http.Header{
+		"X-Method-Rate-Limit":       {"1:10"},
+		"X-Method-Rate-Limit-Count": {"1:10"},
+	})
+
+	if wait := pendingWait(rl, "europe", methodMatchByID); wait <= 9*time.Second || wait > 10*time.Second {
+		t.Errorf("wait once the method limit is reached = %s, want the rest of the 10s window", wait)
+	}
+	if wait := pendingWait(rl, "europe", methodMatchIDsByPUUID); wait != 0 {
+		t.Errorf("wait on another method = %s, want 0", wait)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		// requests are made before the response headers are received
+		requests int
+		headers  http.Header
+		blocked  bool
+	}{
+		{
+			name:    "limits of the key replace the default ones",
+			headers: http.Header{"X-App-Rate-Limit": {"500:10,30000:600"}, "X-App-Rate-Limit-Count": {"500:10,1:600"}},
+			blocked: true,
+		},
+		{
+			name:    "requests left",
+			headers: http.Header{"X-App-Rate-Limit": {"500:10,30000:600"}, "X-App-Rate-Limit-Count": {"499:10,1:600"}},
+			blocked: false,
+		},
+		{
+			name:     "local counts higher than Riot's are kept",
+			requests: 2,
+			headers:  http.Header{"X-App-Rate-Limit": {"2:10"}, "X-App-Rate-Limit-Count": {"1:10"}},
+			blocked:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := NewRateLimiter("2:10")
+			for range tt.requests {
+				if err := rl.Wait(context.Background(), "euw1", methodLeagueEntriesByPUUID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rl.Update("euw1", methodLeagueEntriesByPUUID, tt.headers)
+
+			if wait := pendingWait(rl, "euw1", methodLeagueEntriesByPUUID); (wait > 0) != tt.blocked {
+				t.Errorf("wait after Update = %s, want blocked %t", wait, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestRateLimiterBackoff(t *testing.T) {
+	tests := []struct {
+		limitType string
+		// otherMethodBlocked is whether another method of the host waits too
+		otherMethodBlocked bool
+	}{
+		{limitType: "application", otherMethodBlocked: true},
+		{limitType: "method", otherMethodBlocked: false},
+		{limitType: "service", otherMethodBlocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.limitType, func(t *testing.T) {
+			rl := NewRateLimiter(defaultAppRateLimit)
+			rl.Backoff("euw1", methodLeagueEntriesByPUUID, tt.limitType, 5*time.Second)
+
+			if wait := pendingWait(rl, "euw1", methodLeagueEntriesByPUUID); wait <= 4*time.Second || wait > 5*time.Second {
+				t.Errorf("wait after a 429 = %s, want Retry-After", wait)
+			}
+			if wait := pendingWait(rl, "euw1", methodSummonerByPUUID); (wait > 0) != tt.otherMethodBlocked {
+				t.Errorf("wait on another method = %s, want blocked %t", wait, tt.otherMethodBlocked)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	rl := NewRateLimiter("1:1")
+	if err := rl.Wait(context.Background(), "euw1", methodSummonerByPUUID); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if err := rl.Wait(context.Background(), "euw1", methodSummonerByPUUID); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Wait() returned after %s, want the end of the 1s window", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	rl := NewRateLimiter("1:10")
+	if err := rl.Wait(context.Background(), "euw1", methodSummonerByPUUID); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := rl.Wait(ctx, "euw1", methodSummonerByPUUID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() past the deadline = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// the canceled request isn't counted
+	rl.mu.Lock()
+	count := rl.appBucket("euw1", time.Now()).windows[0].count
+	rl.mu.Unlock()
+	if count != 1 {
+		t.Errorf("%d requests counted, want 1", count)
+	}
+}
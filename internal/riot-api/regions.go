@@ -0,0 +1,120 @@
+package riotapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// platform describes a League of Legends platform (e.g. euw1) and how to route requests for it.
+type platform struct {
+	// regional is the routing value used by match-v5 (americas, asia, europe, sea).
+	regional string
+	// accountRegional is the routing value used by account-v1, which has no sea cluster.
+	accountRegional string
+	// displayName is the short name players know the platform by (e.g. EUW).
+	displayName string
+	// leagueOfGraphs is the region slug used in leagueofgraphs.com URLs.
+	leagueOfGraphs string
+}
+
+// platforms maps every platform routing value to its regional routing values.
+// https://developer.riotgames.com/docs/lol#routing-values
+var platforms = map[string]platform{
+	"br1":  {regional: "americas", accountRegional: "americas", displayName: "BR", leagueOfGraphs: "br"},
+	"la1":  {regional: "americas", accountRegional: "americas", displayName: "LAN", leagueOfGraphs: "lan"},
+	"la2":  {regional: "americas", accountRegional: "americas", displayName: "LAS", leagueOfGraphs: "las"},
+	"na1":  {regional: "americas", accountRegional: "americas", displayName: "NA", leagueOfGraphs: "na"},
+	"jp1":  {regional: "asia", accountRegional: "asia", displayName: "JP", leagueOfGraphs: "jp"},
+	"kr":   {regional: "asia", accountRegional: "asia", displayName: "KR", leagueOfGraphs: "kr"},
+	"eun1": {regional: "europe", accountRegional: "europe", displayName: "EUNE", leagueOfGraphs: "eune"},
+	"euw1": {regional: "europe", accountRegional: "europe", displayName: "EUW", leagueOfGraphs: "euw"},
+	"me1":  {regional: "europe", accountRegional: "europe", displayName: "ME", leagueOfGraphs: "me"},
+	"ru":   {regional: "europe", accountRegional: "europe", displayName: "RU", leagueOfGraphs: "ru"},
+	"tr1":  {regional: "europe", accountRegional: "europe", displayName: "TR", leagueOfGraphs: "tr"},
+	"oc1":  {regional: "sea", accountRegional: "asia", displayName: "OCE", leagueOfGraphs: "oce"},
+	"sg2":  {regional: "sea", accountRegional: "asia", displayName: "SEA", leagueOfGraphs: "sg"},
+}
+
+// NormalizePlatform lowercases and trims a platform routing value, and checks that it is supported.
+func NormalizePlatform(p string) (string, error) {
+	p = strings.ToLower(strings.TrimSpace(p))
+	if _, ok := platforms[p]; !ok {
+		return "", fmt.Errorf("unknown region '%s', expected one of %s", p, strings.Join(Platforms(), ", "))
+	}
+
+	return p, nil
+}
+
+// Platforms returns every supported platform routing value, sorted alphabetically.
+func Platforms() []string {
+	names := make([]string, 0, len(platforms))
+	for name := range platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// PlatformDisplayName returns the short name of a platform (e.g. "EUW" for euw1).
+func PlatformDisplayName(p string) string {
+	if info, ok := platforms[strings.ToLower(p)]; ok {
+		return info.displayName
+	}
+
+	return strings.ToUpper(p)
+}
+
+// LeagueOfGraphsRegion returns the region slug used by leagueofgraphs.com for a platform.
+func LeagueOfGraphsRegion(p string) string {
+	if info, ok := platforms[strings.ToLower(p)]; ok {
+		return info.leagueOfGraphs
+	}
+
+	return strings.ToLower(p)
+}
+
+// PlatformFromMatchID extracts the platform from a match-v5 id (e.g. "EUW1_1234" -> "euw1").
+func PlatformFromMatchID(matchID string) string {
+	p, _, found := strings.Cut(matchID, "_")
+	if !found {
+		return ""
+	}
+
+	return strings.ToLower(p)
+}
+
+// platformOrDefault returns p, or the client's default platform when p is empty.
+func (c *Client) platformOrDefault(p string) string {
+	if p == "" {
+		return c.region
+	}
+
+	return strings.ToLower(p)
+}
+
+// platformHost returns the base URL serving platform routed endpoints (summoner-v4, league-v4...).
+func (c *Client) platformHost(p string) string {
+	return c.routingURL(p)
+}
+
+// regionalHost returns the base URL serving regional routed endpoints (match-v5) for a platform.
+func (c *Client) regionalHost(p string) string {
+	return c.routingURL(platforms[p].regional)
+}
+
+// accountHost returns the base URL serving account-v1 for a platform.
+func (c *Client) accountHost(p string) string {
+	return c.routingURL(platforms[p].accountRegional)
+}
+
+// routingURL returns the base URL of a routing value (e.g. https://euw1.api.riotgames.com),
+// or {baseURL}/{routing} when the client was created with a base URL.
+func (c *Client) routingURL(routing string) string {
+	if c.baseURL != "" {
+		return fmt.Sprintf("%s/%s", c.baseURL, routing)
+	}
+
+	return fmt.Sprintf("https://%s.api.riotgames.com", routing)
+}
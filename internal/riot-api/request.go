@@ -1,73 +1,137 @@
 package riotapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/tristan-derez/league-tracker/internal/utils"
+)
+
+const (
+	// maxRequestAttempts caps how many times a request is sent when Riot answers 429 or 5xx.
+	maxRequestAttempts = 4
+	// serverErrorBaseDelay and serverErrorMaxDelay bound the backoff between retries of 5xx responses.
+	serverErrorBaseDelay = 500 * time.Millisecond
+	serverErrorMaxDelay  = 8 * time.Second
 )
 
 // makeRequest performs an HTTP GET request to the specified URL using the client's API key.
 // It handles rate limiting through the client's rate limiter and manages API-specific errors.
+// method is the Riot API method name of the endpoint, used to track its method rate limit.
+// The request, rate limit waits and retries are abandoned as soon as ctx is done.
 //
 // The function will:
 //  1. Wait for the rate limiter before making the request.
 //  2. Create and send an HTTP GET request with the Riot API key in the header.
-//  3. Handle non-200 status codes, creating a RiotAPIError for detailed error information.
-//  4. Specifically manage rate limit errors (HTTP 429) by respecting the Retry-After header
-//     or using a default wait time, then retrying the request.
-func (c *Client) makeRequest(url string) (*http.Response, error) {
-	c.rateLimiter.Wait()
-
-	req, err := http.NewRequest("GET", url, nil)
+//  3. Update the rate limiter with the rate limit headers of the response.
+//  4. Handle non-200 status codes, returning a RiotAPIError for detailed error information.
+//     A rejected key (see rejectsKey) is flagged as invalid: later requests fail with ErrInvalidAPIKey until
+//     a new key is set. A 403 of another endpoint has the key checked right away.
+//  5. Retry rate limit errors (HTTP 429) once the exceeded limit has been blocked for the Retry-After
+//     header duration, and server errors (HTTP 5xx) after a jittered backoff, up to maxRequestAttempts.
+func (c *Client) makeRequest(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error parsing request URL: %w", err)
 	}
-	req.Header.Set("X-Riot-Token", c.apiKey)
+	host := parsedURL.Host
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error sending request: %w", err)
-	}
+	var apiErr *RiotAPIError
+	for attempt := 0; attempt < maxRequestAttempts; attempt++ {
+		apiKey, err := c.currentAPIKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := c.rateLimiter.Wait(ctx, host, method); err != nil {
+			return nil, fmt.Errorf("error waiting for rate limiter: %w", err)
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close()
+		req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		req.Header.Set("X-Riot-Token", apiKey)
 
-		var errorResponse struct {
-			Status struct {
-				StatusCode int    `json:"status_code"`
-				Message    string `json:"message"`
-			} `json:"status"`
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error sending request: %w", err)
 		}
 
-		body, _ := io.ReadAll(resp.Body)
+		c.rateLimiter.Update(host, method, resp.Header)
 
-		if err := json.Unmarshal(body, &errorResponse); err != nil {
-			// If we cannot parse the error response, use a default message
-			return nil, fmt.Errorf("API returned status code %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
 		}
 
-		if resp.StatusCode == http.StatusTooManyRequests {
-			retryAfter := resp.Header.Get("Retry-After")
-			if retryAfter != "" {
-				seconds, err := strconv.Atoi(retryAfter)
+		apiErr = newRiotAPIError(resp)
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			retryAfter := time.Second
+			if header := resp.Header.Get("Retry-After"); header != "" {
+				seconds, err := strconv.Atoi(header)
 				if err != nil {
 					log.Printf("Error parsing Retry-After header: %v. Using default wait time.", err)
-					time.Sleep(1 * time.Second)
 				} else {
-					time.Sleep(time.Duration(seconds) * time.Second)
+					retryAfter = time.Duration(seconds) * time.Second
 				}
-			} else {
-				time.Sleep(1 * time.Second)
 			}
-			return c.makeRequest(url) // Retry the request
+
+			limitType := resp.Header.Get("X-Rate-Limit-Type")
+			log.Printf("Rate limited by Riot (%s limit) on %s %s, retrying in %s", limitType, host, method, retryAfter)
+			c.rateLimiter.Backoff(host, method, limitType, retryAfter)
+		case resp.StatusCode >= http.StatusInternalServerError:
+			if attempt < maxRequestAttempts-1 {
+				delay := utils.BackoffWithJitter(attempt, serverErrorBaseDelay, serverErrorMaxDelay)
+				log.Printf("Riot returned %d on %s %s, retrying in %s", resp.StatusCode, host, method, delay)
+				if err := sleepContext(ctx, delay); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			// 400, 401, 403, 404... won't succeed on a retry
+			if rejectsKey(method, apiErr) {
+				c.markKeyInvalid(ctx, apiKey, apiErr)
+			} else if apiErr.StatusCode == http.StatusForbidden {
+				c.checkForbiddenKey(ctx, apiKey)
+			}
+			return nil, apiErr
 		}
+	}
+
+	return nil, apiErr
+}
 
-		return nil, fmt.Errorf("%s", errorResponse.Status.Message)
+// newRiotAPIError reads a non-200 response and closes its body.
+// The message is taken from the Riot error payload when it can be parsed, or from the raw body otherwise.
+func newRiotAPIError(resp *http.Response) *RiotAPIError {
+	defer resp.Body.Close()
+
+	var errorResponse struct {
+		Status struct {
+			StatusCode int    `json:"status_code"`
+			Message    string `json:"message"`
+		} `json:"status"`
 	}
 
-	return resp, nil
+	body, _ := io.ReadAll(resp.Body)
+
+	message := string(body)
+	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Status.Message != "" {
+		message = errorResponse.Status.Message
+	}
+
+	return &RiotAPIError{
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		Headers:    resp.Header,
+	}
 }
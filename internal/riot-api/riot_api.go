@@ -1,42 +1,123 @@
 package riotapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Riot API method names, used to track the method rate limit of each endpoint.
+const (
+	methodAccountByRiotID      = "account-v1.getByRiotId"
+	methodAccountByPUUID       = "account-v1.getByPuuid"
+	methodSummonerByPUUID      = "summoner-v4.getByPUUID"
+	methodLeagueEntriesByPUUID = "league-v4.getLeagueEntriesByPUUID"
+	methodMatchByID            = "match-v5.getMatch"
+	methodMatchIDsByPUUID      = "match-v5.getMatchIdsByPUUID"
+)
+
+const (
+	// matchIDsPageSize is the number of match ids requested per match-v5 page.
+	matchIDsPageSize = 20
+	// maxCatchUpMatches caps how many missed matches are fetched for a summoner in a single poll,
+	// the newer ones are left for the next polls.
+	maxCatchUpMatches = 20
+)
+
 type Client struct {
-	apiKey      string
+	keyMu sync.RWMutex
+	// apiKey is the key sent to Riot, keyInvalid is set once Riot rejected it
+	apiKey     string
+	keyInvalid bool
+	// keyChecking is set while a 403 is checked against lol-status-v4, keyAcceptedAt is when such a check last
+	// succeeded with apiKey, see checkForbiddenKey
+	keyChecking   bool
+	keyAcceptedAt time.Time
+	// onInvalidKey is called when Riot rejects the API key, optional
+	onInvalidKey func(err error)
+
 	httpClient  *http.Client
 	region      string
 	rateLimiter *RateLimiter
+	// baseURL replaces https://{routing}.api.riotgames.com with {baseURL}/{routing} when set
+	baseURL string
+	// matches and timelines keep recent matches, several tracked summoners often play the same one
+	matches   *matchCache[matchInfo]
+	timelines *matchCache[*MatchTimeline]
+}
+
+// ClientOption customizes a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithTransport sends the requests of the client through transport, e.g. a RecordingTransport or a ReplayTransport.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.httpClient.Transport = transport
+	}
+}
+
+// WithBaseURL sends the requests of the client to baseURL instead of the Riot API, the routing value
+// (e.g. euw1, europe) being the first segment of the path: {baseURL}/euw1/lol/summoner/v4/...
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
 }
 
 // NewClient creates and returns a new Client instance for interacting with the Riot API.
-// It initializes the client with the provided API key and region, and sets up a rate limiter.
-func NewClient(apiKey, region string) *Client {
-	return &Client{
+// It initializes the client with the provided API key and default platform (region), and sets up a rate limiter.
+// The default platform is used whenever a method is called with an empty platform.
+func NewClient(apiKey, region string, opts ...ClientOption) *Client {
+	c := &Client{
 		apiKey: apiKey,
 		httpClient: &http.Client{
 			Timeout: time.Second * 10,
 		},
-		region:      region,
-		rateLimiter: NewRateLimiter(20, 100), // 20 requests per second, burst of 100
+		region:      strings.ToLower(region),
+		rateLimiter: NewRateLimiter(defaultAppRateLimit),
+		matches:     newMatchCache[matchInfo](matchCacheSize),
+		timelines:   newMatchCache[*MatchTimeline](timelineCacheSize),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
+}
+
+// RequestsPerSecond returns the sustained request rate the app rate limit allows for a platform: the lowest one of
+// the routing hosts it uses (platform, match-v5 and account-v1), each one having its own app limit.
+func (c *Client) RequestsPerSecond(platform string) float64 {
+	platform = c.platformOrDefault(platform)
+
+	rate := math.Inf(1)
+	for _, baseURL := range []string{c.platformHost(platform), c.regionalHost(platform), c.accountHost(platform)} {
+		parsedURL, err := url.Parse(baseURL)
+		if err != nil {
+			continue
+		}
+		rate = math.Min(rate, c.rateLimiter.RequestsPerSecond(parsedURL.Host))
+	}
+
+	return rate
 }
 
 // GetAccountPUUIDBySummonerName fetch the puuid of a summoner with the gameName and tagLine.
 //   - gameName#tagLine
-func (c *Client) GetAccountPUUIDBySummonerName(gameName, tagLine string) (*Account, error) {
+//   - platform is used to pick the closest account-v1 cluster.
+func (c *Client) GetAccountPUUIDBySummonerName(ctx context.Context, platform, gameName, tagLine string) (*Account, error) {
 	encodedName := url.PathEscape(gameName)
 	encodedTag := url.PathEscape(tagLine)
-	url := fmt.Sprintf("https://europe.api.riotgames.com/riot/account/v1/accounts/by-riot-id/%s/%s", encodedName, encodedTag)
+	url := fmt.Sprintf("%s/riot/account/v1/accounts/by-riot-id/%s/%s", c.accountHost(c.platformOrDefault(platform)), encodedName, encodedTag)
 
-	resp, err := c.makeRequest(url)
+	resp, err := c.makeRequest(ctx, methodAccountByRiotID, url)
 	if err != nil {
 		return nil, err
 	}
@@ -50,10 +131,11 @@ func (c *Client) GetAccountPUUIDBySummonerName(gameName, tagLine string) (*Accou
 	return &account, nil
 }
 
-func (c *Client) GetAccountByPUUID(puuid string) (*Account, error) {
-	url := fmt.Sprintf("https://europe.api.riotgames.com/riot/account/v1/accounts/by-puuid/%s", puuid)
+// GetAccountByPUUID fetch the Riot ID of an account by its puuid.
+func (c *Client) GetAccountByPUUID(ctx context.Context, platform, puuid string) (*Account, error) {
+	url := fmt.Sprintf("%s/riot/account/v1/accounts/by-puuid/%s", c.accountHost(c.platformOrDefault(platform)), puuid)
 
-	resp, err := c.makeRequest(url)
+	resp, err := c.makeRequest(ctx, methodAccountByPUUID, url)
 	if err != nil {
 		return nil, err
 	}
@@ -68,10 +150,11 @@ func (c *Client) GetAccountByPUUID(puuid string) (*Account, error) {
 }
 
 // GetSummonerByPUUID fetch summoner data by their puuid.
-func (c *Client) GetSummonerByPUUID(puuid string) (*Summoner, error) {
-	url := fmt.Sprintf("https://%s.api.riotgames.com/lol/summoner/v4/summoners/by-puuid/%s", c.region, puuid)
+func (c *Client) GetSummonerByPUUID(ctx context.Context, platform, puuid string) (*Summoner, error) {
+	platform = c.platformOrDefault(platform)
+	url := fmt.Sprintf("%s/lol/summoner/v4/summoners/by-puuid/%s", c.platformHost(platform), puuid)
 
-	resp, err := c.makeRequest(url)
+	resp, err := c.makeRequest(ctx, methodSummonerByPUUID, url)
 	if err != nil {
 		return nil, err
 	}
@@ -81,15 +164,16 @@ func (c *Client) GetSummonerByPUUID(puuid string) (*Summoner, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&summoner); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
+	summoner.Region = platform
 
 	return &summoner, nil
 }
 
-// GetSummonerRank fetch summoner current tier and rank from Riot API.
-func (c *Client) GetSummonerRank(summonerPUUID string) (*LeagueEntry, error) {
-	url := fmt.Sprintf("https://%s.api.riotgames.com/lol/league/v4/entries/by-puuid/%s", c.region, summonerPUUID)
+// GetLeagueEntries fetch every league entry (one per ranked queue the summoner is placed in) from Riot API.
+func (c *Client) GetLeagueEntries(ctx context.Context, platform, summonerPUUID string) ([]LeagueEntry, error) {
+	url := fmt.Sprintf("%s/lol/league/v4/entries/by-puuid/%s", c.platformHost(c.platformOrDefault(platform)), summonerPUUID)
 
-	resp, err := c.makeRequest(url)
+	resp, err := c.makeRequest(ctx, methodLeagueEntriesByPUUID, url)
 	if err != nil {
 		return nil, err
 	}
@@ -100,42 +184,71 @@ func (c *Client) GetSummonerRank(summonerPUUID string) (*LeagueEntry, error) {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	// Look for solo queue entry
+	return leagueEntries, nil
+}
+
+// GetSummonerRank fetch summoner current tier and rank in ranked solo/duo from Riot API.
+func (c *Client) GetSummonerRank(ctx context.Context, platform, summonerPUUID string) (*LeagueEntry, error) {
+	leagueEntries, err := c.GetLeagueEntries(ctx, platform, summonerPUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindLeagueEntry(leagueEntries, QueueTypeRankedSolo), nil
+}
+
+// FindLeagueEntry returns the entry of a queue type among the league entries of a summoner,
+// or an UNRANKED entry when the summoner isn't placed in that queue.
+func FindLeagueEntry(leagueEntries []LeagueEntry, queueType string) *LeagueEntry {
 	for _, entry := range leagueEntries {
-		if entry.QueueType == "RANKED_SOLO_5x5" {
-			return &entry, nil
+		if entry.QueueType == queueType {
+			return &entry
 		}
 	}
 
 	return &LeagueEntry{
-		QueueType:    "RANKED_SOLO_5x5",
+		QueueType:    queueType,
 		Tier:         "UNRANKED",
 		Rank:         "",
 		LeaguePoints: 0,
-	}, nil
+	}
 }
 
 // GetMatchData fetch summoner match data using the matchID, summonerPUUID is used to find participant.
-func (c *Client) GetMatchData(matchID string, summonerPUUID string) (*MatchData, error) {
-	url := fmt.Sprintf("https://europe.api.riotgames.com/lol/match/v5/matches/%s", matchID)
+// The regional host is derived from the platform prefix of the matchID. Matches fetched recently are
+// served from memory, for the other tracked summoners of the match.
+func (c *Client) GetMatchData(ctx context.Context, matchID string, summonerPUUID string) (*MatchData, error) {
+	info, err := c.matches.get(ctx, matchID, func(ctx context.Context) (matchInfo, error) {
+		return c.fetchMatchInfo(ctx, matchID)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := c.makeRequest(url)
+	participant, err := findParticipant(info.Participants, summonerPUUID)
 	if err != nil {
 		return nil, err
 	}
+
+	return createMatchData(matchID, info, *participant), nil
+}
+
+// fetchMatchInfo fetches a match from match-v5.
+func (c *Client) fetchMatchInfo(ctx context.Context, matchID string) (matchInfo, error) {
+	url := fmt.Sprintf("%s/lol/match/v5/matches/%s", c.regionalHost(c.platformOrDefault(PlatformFromMatchID(matchID))), matchID)
+
+	resp, err := c.makeRequest(ctx, methodMatchByID, url)
+	if err != nil {
+		return matchInfo{}, err
+	}
 	defer resp.Body.Close()
 
 	var matchResp matchResponse
 	if err := json.NewDecoder(resp.Body).Decode(&matchResp); err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
+		return matchInfo{}, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	participant, err := findParticipant(matchResp.Info.Participants, summonerPUUID)
-	if err != nil {
-		return nil, err
-	}
-
-	return createMatchData(matchID, matchResp.Info, *participant), nil
+	return matchResp.Info, nil
 }
 
 // findParticipant searches for a participant in a match by their PUUID.
@@ -149,8 +262,10 @@ func findParticipant(participants []participant, summonerPUUID string) (*partici
 	return nil, fmt.Errorf("summoner not found in match data")
 }
 
-func (c *Client) GetPlacementStatus(puuid string) (*PlacementStatus, error) {
-	matchIDs, err := c.GetRankedSoloMatchIDs(puuid, 5)
+// GetPlacementStatus counts the placement games a summoner played in a ranked queue since splitStart,
+// the start of the current split.
+func (c *Client) GetPlacementStatus(ctx context.Context, platform, puuid string, queueID int, splitStart time.Time) (*PlacementStatus, error) {
+	matchIDs, err := c.GetMatchIDs(ctx, platform, puuid, queueID, 5)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching match IDs: %w", err)
 	}
@@ -162,20 +277,17 @@ func (c *Client) GetPlacementStatus(puuid string) (*PlacementStatus, error) {
 		Losses:         0,
 	}
 
-	currentSplit := getCurrentSplit()
-
 	for _, matchID := range matchIDs {
-		match, err := c.GetMatchData(matchID, puuid)
+		match, err := c.GetMatchData(ctx, matchID, puuid)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching match data: %w", err)
 		}
 
-		if match.QueueID != 420 || match.GameDuration <= 210 {
+		if match.QueueID != queueID || match.GameDuration <= 210 {
 			continue
 		}
 
-		matchSplit := getSplitFromTimestamp(match.GameEndTimestamp)
-		if matchSplit != currentSplit {
+		if match.GameEndTimestamp < splitStart.UnixMilli() {
 			continue // Skip matches from previous splits
 		}
 
@@ -195,46 +307,6 @@ func (c *Client) GetPlacementStatus(puuid string) (*PlacementStatus, error) {
 	return placementStatus, nil
 }
 
-func getCurrentSplit() int {
-	now := time.Now()
-	year := now.Year()
-	splitStartDates := []time.Time{
-		time.Date(year, time.January, 10, 0, 0, 0, 0, time.UTC),
-		time.Date(year, time.May, 25, 0, 0, 0, 0, time.UTC),
-		time.Date(year, time.September, 25, 0, 0, 0, 0, time.UTC),
-	}
-
-	for i, startDate := range splitStartDates {
-		if now.Before(startDate) {
-			if i == 0 {
-				return 3 // Last split of previous year
-			}
-			return i
-		}
-	}
-	return 3 // Last split of the year
-}
-
-func getSplitFromTimestamp(timestamp int64) int {
-	matchTime := time.Unix(timestamp/1000, 0)
-	year := matchTime.Year()
-	splitStartDates := []time.Time{
-		time.Date(year, time.January, 10, 0, 0, 0, 0, time.UTC),
-		time.Date(year, time.May, 25, 0, 0, 0, 0, time.UTC),
-		time.Date(year, time.September, 25, 0, 0, 0, 0, time.UTC),
-	}
-
-	for i, startDate := range splitStartDates {
-		if matchTime.Before(startDate) {
-			if i == 0 {
-				return 3 // Last split of previous year
-			}
-			return i
-		}
-	}
-	return 3 // Last split of the year
-}
-
 // createMatchData constructs a MatchData struct from the given match information and participant data.
 // It takes the matchID, general match info, and specific participant data as input.
 func createMatchData(matchID string, info matchInfo, participant participant) *MatchData {
@@ -267,14 +339,60 @@ func createMatchData(matchID string, info matchInfo, participant participant) *M
 		WardsKilled:                 participant.WardsKilled,
 		WardsPlaced:                 participant.WardsPlaced,
 		Win:                         participant.Win,
+		Participants:                createMatchParticipants(info.Participants),
+	}
+}
+
+// createMatchParticipants constructs the roster of a match from the participants of its match-v5 info.
+func createMatchParticipants(participants []participant) []MatchParticipant {
+	roster := make([]MatchParticipant, 0, len(participants))
+	for _, p := range participants {
+		roster = append(roster, MatchParticipant{
+			PUUID:                       p.Puuid,
+			RiotIDGameName:              p.RiotIdGameName,
+			RiotIDTagLine:               p.RiotIdTagline,
+			ChampionID:                  p.ChampionId,
+			ChampionName:                p.ChampionName,
+			TeamID:                      p.TeamId,
+			TeamPosition:                p.TeamPosition,
+			Kills:                       p.Kills,
+			Deaths:                      p.Deaths,
+			Assists:                     p.Assists,
+			TotalDamageDealtToChampions: p.TotalDamageDealtToChampions,
+			GoldEarned:                  p.GoldEarned,
+			TotalMinionsKilled:          p.TotalMinionsKilled,
+			NeutralMinionsKilled:        p.NeutralMinionsKilled,
+			Items:                       []int{p.Item0, p.Item1, p.Item2, p.Item3, p.Item4, p.Item5, p.Item6},
+			Win:                         p.Win,
+		})
+	}
+
+	return roster
+}
+
+// GetMatchIDs retrieves last game(s) id(s) of a summoner in a queue.
+func (c *Client) GetMatchIDs(ctx context.Context, platform, puuid string, queueID, count int) ([]string, error) {
+	matchIDs, err := c.getMatchIDsPage(ctx, platform, puuid, queueID, 0, 0, count)
+	if err != nil {
+		return nil, err
 	}
+
+	if len(matchIDs) == 0 {
+		return nil, nil
+	}
+
+	return matchIDs, nil
 }
 
-// GetRankedSoloMatchIDs retrieves last game(s) id(s) from a summoner.
-func (c *Client) GetRankedSoloMatchIDs(puuid string, count int) ([]string, error) {
-	url := fmt.Sprintf("https://europe.api.riotgames.com/lol/match/v5/matches/by-puuid/%s/ids?queue=420&type=ranked&count=%d", puuid, count)
+// getMatchIDsPage retrieves one page of match ids of a queue, newest first.
+// startTime is an epoch timestamp in seconds, it is ignored when zero.
+func (c *Client) getMatchIDsPage(ctx context.Context, platform, puuid string, queueID int, startTime int64, start, count int) ([]string, error) {
+	url := fmt.Sprintf("%s/lol/match/v5/matches/by-puuid/%s/ids?queue=%d&start=%d&count=%d", c.regionalHost(c.platformOrDefault(platform)), puuid, queueID, start, count)
+	if startTime > 0 {
+		url += fmt.Sprintf("&startTime=%d", startTime)
+	}
 
-	resp, err := c.makeRequest(url)
+	resp, err := c.makeRequest(ctx, methodMatchIDsByPUUID, url)
 	if err != nil {
 		return nil, err
 	}
@@ -285,18 +403,14 @@ func (c *Client) GetRankedSoloMatchIDs(puuid string, count int) ([]string, error
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	if len(matchIDs) == 0 {
-		return nil, nil
-	}
-
 	return matchIDs, nil
 }
 
-// GetLastRankedSoloMatchData fetch the last match of a summoner
-// by fetching the last ranked game with summonerPUUID,
+// GetLastMatchData fetch the last match of a summoner in a queue
+// by fetching the last game id with summonerPUUID,
 // and returns the match data.
-func (c *Client) GetLastRankedSoloMatchData(summonerPUUID string) (*MatchData, error) {
-	matchIDs, err := c.GetRankedSoloMatchIDs(summonerPUUID, 1)
+func (c *Client) GetLastMatchData(ctx context.Context, platform, summonerPUUID string, queueID int) (*MatchData, error) {
+	matchIDs, err := c.GetMatchIDs(ctx, platform, summonerPUUID, queueID, 1)
 	if err != nil {
 		return nil, fmt.Errorf("error getting match IDs: %w", err)
 	}
@@ -305,7 +419,7 @@ func (c *Client) GetLastRankedSoloMatchData(summonerPUUID string) (*MatchData, e
 		return nil, nil
 	}
 
-	matchData, err := c.GetMatchData(matchIDs[0], summonerPUUID)
+	matchData, err := c.GetMatchData(ctx, matchIDs[0], summonerPUUID)
 	if err != nil {
 		return nil, fmt.Errorf("error getting match data: %w", err)
 	}
@@ -313,59 +427,71 @@ func (c *Client) GetLastRankedSoloMatchData(summonerPUUID string) (*MatchData, e
 	return matchData, nil
 }
 
-// GetNewMatchForSummoner checks if there's a new ranked solo/duo match for a summoner we're already tracking.
-// It returns a boolean indicating if there's a new match, and the new match data if there is one.
-func (c *Client) GetNewMatchForSummoner(summonerPUUID string, lastKnownMatchID string) (bool, *MatchData, error) {
-	matchIDs, err := c.GetRankedSoloMatchIDs(summonerPUUID, 1)
+// GetNewMatchesForSummoner returns every match of a queue a tracked summoner played after lastKnownMatchID,
+// oldest first, so games finished between two polls (or while the bot was down) are not skipped.
+//   - lastKnownGameCreation (epoch ms) narrows the search with match-v5 startTime when known.
+//   - When no match is known yet, only the latest match is returned.
+//   - At most the maxCatchUpMatches oldest new matches are returned, more reports that newer ones are left.
+//     Once the returned matches are stored, the next call picks up after them.
+func (c *Client) GetNewMatchesForSummoner(ctx context.Context, platform, summonerPUUID string, queueID int, lastKnownMatchID string, lastKnownGameCreation int64) (matches []*MatchData, more bool, err error) {
+	newMatchIDs, err := c.getMatchIDsSince(ctx, platform, summonerPUUID, queueID, lastKnownMatchID, lastKnownGameCreation)
 	if err != nil {
-		return false, nil, fmt.Errorf("error getting last match ID: %w", err)
+		return nil, false, fmt.Errorf("error getting match IDs: %w", err)
 	}
 
-	if len(matchIDs) == 0 {
-		return false, nil, nil // No new matches
+	// ids are newest first, the oldest ones are kept
+	if len(newMatchIDs) > maxCatchUpMatches {
+		newMatchIDs = newMatchIDs[len(newMatchIDs)-maxCatchUpMatches:]
+		more = true
 	}
 
-	latestMatchID := matchIDs[0]
-
-	if latestMatchID == lastKnownMatchID {
-		return false, nil, nil // No new matches
-	}
+	matches = make([]*MatchData, 0, len(newMatchIDs))
 
-	matchData, err := c.GetMatchData(latestMatchID, summonerPUUID)
-	if err != nil {
-		return false, nil, fmt.Errorf("error getting match data: %w", err)
+	// match-v5 lists ids newest first, walk them backward to keep chronological order
+	for i := len(newMatchIDs) - 1; i >= 0; i-- {
+		matchData, err := c.GetMatchData(ctx, newMatchIDs[i], summonerPUUID)
+		if err != nil {
+			return nil, false, fmt.Errorf("error getting match data for %s: %w", newMatchIDs[i], err)
+		}
+		matches = append(matches, matchData)
 	}
 
-	return true, matchData, nil
+	return matches, more, nil
 }
 
-// GetCurrentDDragonVersion fetch the current version of DDragon Version.
-func (c *Client) GetCurrentDDragonVersion() (string, error) {
-	const defaultVersion string = "14.15.1"
-	url := "https://ddragon.leagueoflegends.com/api/versions.json"
-
-	resp, err := c.makeRequest(url)
-	if err != nil {
-		return defaultVersion, fmt.Errorf("error making request: %w. using default version", err)
+// getMatchIDsSince pages through match-v5 ids of a queue (newest first) until lastKnownMatchID is reached,
+// and returns the ids of every match played after it. startTime keeps the pages from going further back.
+func (c *Client) getMatchIDsSince(ctx context.Context, platform, summonerPUUID string, queueID int, lastKnownMatchID string, lastKnownGameCreation int64) ([]string, error) {
+	if lastKnownMatchID == "" {
+		return c.GetMatchIDs(ctx, platform, summonerPUUID, queueID, 1)
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return defaultVersion, fmt.Errorf("error reading response body: %w. using default version", err)
+	var startTime int64
+	if lastKnownGameCreation > 0 {
+		startTime = lastKnownGameCreation / 1000
 	}
 
-	var versions []string
-	err = json.Unmarshal(body, &versions)
-	if err != nil {
-		return defaultVersion, fmt.Errorf("error unmarshaling JSON: %w. using default version", err)
-	}
+	var newMatchIDs []string
 
-	if len(versions) == 0 {
-		return defaultVersion, fmt.Errorf("no versions found in the response. using default version")
+	for start := 0; ; start += matchIDsPageSize {
+		page, err := c.getMatchIDsPage(ctx, platform, summonerPUUID, queueID, startTime, start, matchIDsPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, matchID := range page {
+			if matchID == lastKnownMatchID {
+				return newMatchIDs, nil
+			}
+			newMatchIDs = append(newMatchIDs, matchID)
+		}
+
+		if len(page) < matchIDsPageSize {
+			break
+		}
 	}
 
-	return versions[0], nil
+	return newMatchIDs, nil
 }
 
 type Account struct {
@@ -375,15 +501,18 @@ type Account struct {
 }
 
 type Summoner struct {
-	RiotSummonerID string `json:"id"`
-	RiotAccountID  string `json:"accountId"`
-	SummonerPUUID  string `json:"puuid"`
-	ProfileIconID  int    `json:"profileIconId"`
-	RevisionDate   int64  `json:"revisionDate"`
-	SummonerLevel  int    `json:"summonerLevel"`
-	Name           string
-	Rank           string
-	LeaguePoints   int
+	RiotSummonerID   string `json:"id"`
+	RiotAccountID    string `json:"accountId"`
+	SummonerPUUID    string `json:"puuid"`
+	ProfileIconID    int    `json:"profileIconId"`
+	RevisionDate     int64  `json:"revisionDate"`
+	SummonerLevel    int    `json:"summonerLevel"`
+	Region           string
+	Name             string
+	Rank             string
+	LeaguePoints     int
+	FlexRank         string
+	FlexLeaguePoints int
 }
 
 type LeagueEntry struct {
@@ -426,6 +555,31 @@ type MatchData struct {
 	WardsKilled                 int
 	WardsPlaced                 int
 	Win                         bool
+	// Participants holds every player of the match, the tracked summoner included
+	Participants []MatchParticipant
+	// Laning holds the stats computed from the match timeline, nil when the timeline wasn't fetched
+	Laning *LaningStats
+}
+
+// MatchParticipant is one of the players of a match.
+type MatchParticipant struct {
+	PUUID                       string
+	RiotIDGameName              string
+	RiotIDTagLine               string
+	ChampionID                  int
+	ChampionName                string
+	TeamID                      int
+	TeamPosition                string
+	Kills                       int
+	Deaths                      int
+	Assists                     int
+	TotalDamageDealtToChampions int
+	GoldEarned                  int
+	TotalMinionsKilled          int
+	NeutralMinionsKilled        int
+	// Items holds the ids of the 6 item slots then the trinket, 0 for an empty slot
+	Items []int
+	Win   bool
 }
 
 type matchResponse struct {
@@ -444,7 +598,19 @@ type matchInfo struct {
 }
 
 type participant struct {
+	ChampionId                  int       `json:"championId"`
 	ChampionName                string    `json:"championName"`
+	RiotIdGameName              string    `json:"riotIdGameName"`
+	RiotIdTagline               string    `json:"riotIdTagline"`
+	TeamId                      int       `json:"teamId"`
+	GoldEarned                  int       `json:"goldEarned"`
+	Item0                       int       `json:"item0"`
+	Item1                       int       `json:"item1"`
+	Item2                       int       `json:"item2"`
+	Item3                       int       `json:"item3"`
+	Item4                       int       `json:"item4"`
+	Item5                       int       `json:"item5"`
+	Item6                       int       `json:"item6"`
 	Kills                       int       `json:"kills"`
 	Deaths                      int       `json:"deaths"`
 	Assists                     int       `json:"assists"`
@@ -465,6 +631,8 @@ type challenge struct {
 	KillParticipation    float64 `json:"killParticipation"`
 }
 
+// PlacementStatus counts the placement games of a summoner in a ranked queue, IsInPlacements being false once
+// the 5 games are played.
 type PlacementStatus struct {
 	IsInPlacements bool
 	TotalGames     int
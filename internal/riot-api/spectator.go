@@ -0,0 +1,66 @@
+package riotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const methodActiveGameByPUUID = "spectator-v5.getCurrentGameInfoByPuuid"
+
+// ActiveGame is a game currently being played, as returned by spectator-v5.
+type ActiveGame struct {
+	GameID            int64                   `json:"gameId"`
+	GameQueueConfigID int                     `json:"gameQueueConfigId"`
+	GameStartTime     int64                   `json:"gameStartTime"`
+	GameLength        int64                   `json:"gameLength"`
+	PlatformID        string                  `json:"platformId"`
+	Participants      []ActiveGameParticipant `json:"participants"`
+}
+
+// ActiveGameParticipant is a player of an ActiveGame.
+type ActiveGameParticipant struct {
+	PUUID      string `json:"puuid"`
+	RiotID     string `json:"riotId"`
+	ChampionID int    `json:"championId"`
+	TeamID     int    `json:"teamId"`
+}
+
+// MatchID returns the match-v5 id the game will have once it is over (e.g. "EUW1_1234").
+func (g *ActiveGame) MatchID() string {
+	return fmt.Sprintf("%s_%d", strings.ToUpper(g.PlatformID), g.GameID)
+}
+
+// FindParticipant returns the participant of the game with the given puuid, or nil.
+func (g *ActiveGame) FindParticipant(puuid string) *ActiveGameParticipant {
+	for i := range g.Participants {
+		if g.Participants[i].PUUID == puuid {
+			return &g.Participants[i]
+		}
+	}
+
+	return nil
+}
+
+// GetActiveGame fetch the game a summoner is currently playing.
+// It returns nil without error when the summoner is not in game.
+func (c *Client) GetActiveGame(ctx context.Context, platform, puuid string) (*ActiveGame, error) {
+	url := fmt.Sprintf("%s/lol/spectator/v5/active-games/by-summoner/%s", c.platformHost(c.platformOrDefault(platform)), puuid)
+
+	resp, err := c.makeRequest(ctx, methodActiveGameByPUUID, url)
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var game ActiveGame
+	if err := json.NewDecoder(resp.Body).Decode(&game); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &game, nil
+}
@@ -0,0 +1,138 @@
+package riotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const methodPlatformStatus = "lol-status-v4.getPlatformData"
+
+// statusLocale is the locale of the incident titles and updates shown to guilds.
+const statusLocale = "en_US"
+
+// PlatformStatus is the status of a platform, as returned by lol-status-v4.
+type PlatformStatus struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Maintenances []StatusIncident `json:"maintenances"`
+	Incidents    []StatusIncident `json:"incidents"`
+}
+
+// StatusIncident is an incident or a maintenance window of a platform.
+type StatusIncident struct {
+	ID int64 `json:"id"`
+	// MaintenanceStatus is scheduled, in_progress or complete, empty for incidents
+	MaintenanceStatus string `json:"maintenance_status"`
+	// IncidentSeverity is info, warning or critical
+	IncidentSeverity string          `json:"incident_severity"`
+	Titles           []StatusContent `json:"titles"`
+	Updates          []StatusUpdate  `json:"updates"`
+	CreatedAt        string          `json:"created_at"`
+}
+
+// StatusContent is a text of a StatusIncident in a given locale.
+type StatusContent struct {
+	Locale  string `json:"locale"`
+	Content string `json:"content"`
+}
+
+// StatusUpdate is an update posted on a StatusIncident.
+type StatusUpdate struct {
+	ID           int64           `json:"id"`
+	Translations []StatusContent `json:"translations"`
+	CreatedAt    string          `json:"created_at"`
+}
+
+// Title returns the English title of the incident.
+func (i StatusIncident) Title() string {
+	return localizedContent(i.Titles)
+}
+
+// LatestUpdate returns the English text of the last update posted on the incident, or "" if there is none.
+func (i StatusIncident) LatestUpdate() string {
+	if len(i.Updates) == 0 {
+		return ""
+	}
+
+	latest := i.Updates[0]
+	for _, update := range i.Updates[1:] {
+		if update.CreatedAt > latest.CreatedAt {
+			latest = update
+		}
+	}
+
+	return localizedContent(latest.Translations)
+}
+
+// Status types of a StatusIncident.
+const (
+	StatusTypeIncident    = "incident"
+	StatusTypeMaintenance = "maintenance"
+)
+
+// Type returns StatusTypeMaintenance for maintenance windows and StatusTypeIncident for incidents.
+// Their ids are numbered separately, so an incident and a maintenance window can share one.
+func (i StatusIncident) Type() string {
+	if i.MaintenanceStatus != "" {
+		return StatusTypeMaintenance
+	}
+
+	return StatusTypeIncident
+}
+
+// AffectsMatchHistory reports whether match history, which match-v5 serves, is unavailable because of the incident.
+// A maintenance in progress takes the whole platform down. lol-status-v4 has no structured field for the service
+// an incident affects, so the English title and updates of incidents are searched.
+func (i StatusIncident) AffectsMatchHistory() bool {
+	if i.Type() == StatusTypeMaintenance {
+		return i.MaintenanceStatus == "in_progress"
+	}
+
+	texts := []string{i.Title()}
+	for _, update := range i.Updates {
+		texts = append(texts, localizedContent(update.Translations))
+	}
+
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), "match history") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// localizedContent returns the English content, or the first one when there is no English translation.
+func localizedContent(contents []StatusContent) string {
+	for _, content := range contents {
+		if content.Locale == statusLocale {
+			return content.Content
+		}
+	}
+
+	if len(contents) > 0 {
+		return contents[0].Content
+	}
+
+	return ""
+}
+
+// GetPlatformStatus fetch the active incidents and maintenance windows of a platform.
+func (c *Client) GetPlatformStatus(ctx context.Context, platform string) (*PlatformStatus, error) {
+	url := fmt.Sprintf("%s/lol/status/v4/platform-data", c.platformHost(c.platformOrDefault(platform)))
+
+	resp, err := c.makeRequest(ctx, methodPlatformStatus, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var status PlatformStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,226 @@
+package riotapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+const methodMatchTimelineByID = "match-v5.getTimeline"
+
+// Minutes at which laning differences are measured.
+const (
+	laningMinuteEarly = 10
+	laningMinuteLate  = 15
+)
+
+// MatchTimeline is the minute by minute timeline of a match, as returned by match-v5.
+type MatchTimeline struct {
+	Info timelineInfo `json:"info"`
+}
+
+type timelineInfo struct {
+	// Frames holds one frame per minute, the first one at the start of the game
+	Frames       []timelineFrame       `json:"frames"`
+	Participants []timelineParticipant `json:"participants"`
+}
+
+type timelineParticipant struct {
+	ParticipantID int    `json:"participantId"`
+	PUUID         string `json:"puuid"`
+}
+
+type timelineFrame struct {
+	Timestamp         int64                       `json:"timestamp"`
+	ParticipantFrames map[string]participantFrame `json:"participantFrames"`
+	Events            []timelineEvent             `json:"events"`
+}
+
+type participantFrame struct {
+	ParticipantID       int `json:"participantId"`
+	TotalGold           int `json:"totalGold"`
+	XP                  int `json:"xp"`
+	MinionsKilled       int `json:"minionsKilled"`
+	JungleMinionsKilled int `json:"jungleMinionsKilled"`
+}
+
+type timelineEvent struct {
+	Type                    string `json:"type"`
+	Timestamp               int64  `json:"timestamp"`
+	KillerID                int    `json:"killerId"`
+	KillerTeamID            int    `json:"killerTeamId"`
+	VictimID                int    `json:"victimId"`
+	TeamID                  int    `json:"teamId"`
+	AssistingParticipantIDs []int  `json:"assistingParticipantIds"`
+}
+
+// LaningStats sums up how a player did against their lane opponent and in the fights that matter.
+type LaningStats struct {
+	// OpponentChampion is the champion of the lane opponent, empty when no opponent plays the same position
+	OpponentChampion string
+	// At10 and At15 are the differences with the lane opponent, nil when there is no opponent or the game
+	// ended before that minute
+	At10 *LaningDiff
+	At15 *LaningDiff
+	// FirstBloodKill, FirstBloodAssist and FirstBloodVictim report how the player was involved in the first kill
+	FirstBloodKill   bool
+	FirstBloodAssist bool
+	FirstBloodVictim bool
+	// TeamObjectives is the number of epic monsters and buildings taken by the player's team,
+	// ObjectivesParticipated the number of them the player took part in
+	TeamObjectives         int
+	ObjectivesParticipated int
+}
+
+// LaningDiff is the difference between a player and their lane opponent at a given minute, positive when ahead.
+type LaningDiff struct {
+	Gold int
+	CS   int
+	XP   int
+}
+
+// GetMatchTimeline fetch the timeline of a match. The regional host is derived from the platform prefix of the matchID.
+// Timelines fetched recently are served from memory, for the other tracked summoners of the match.
+func (c *Client) GetMatchTimeline(ctx context.Context, matchID string) (*MatchTimeline, error) {
+	return c.timelines.get(ctx, matchID, func(ctx context.Context) (*MatchTimeline, error) {
+		return c.fetchMatchTimeline(ctx, matchID)
+	})
+}
+
+// fetchMatchTimeline fetches the timeline of a match from match-v5.
+func (c *Client) fetchMatchTimeline(ctx context.Context, matchID string) (*MatchTimeline, error) {
+	url := fmt.Sprintf("%s/lol/match/v5/matches/%s/timeline", c.regionalHost(c.platformOrDefault(PlatformFromMatchID(matchID))), matchID)
+
+	resp, err := c.makeRequest(ctx, methodMatchTimelineByID, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var timeline MatchTimeline
+	if err := json.NewDecoder(resp.Body).Decode(&timeline); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return &timeline, nil
+}
+
+// LaningStats computes the laning stats of the player with the given puuid.
+// participants is the roster of the match, used to find the teams and the lane opponent.
+func (t *MatchTimeline) LaningStats(puuid string, participants []MatchParticipant) (*LaningStats, error) {
+	participantIDs := make(map[string]int, len(t.Info.Participants))
+	for _, p := range t.Info.Participants {
+		participantIDs[p.PUUID] = p.ParticipantID
+	}
+
+	playerID, ok := participantIDs[puuid]
+	if !ok {
+		return nil, fmt.Errorf("summoner not found in match timeline")
+	}
+
+	teams := make(map[int]int, len(participants))
+	var player *MatchParticipant
+	for i, p := range participants {
+		teams[participantIDs[p.PUUID]] = p.TeamID
+		if p.PUUID == puuid {
+			player = &participants[i]
+		}
+	}
+
+	if player == nil {
+		return nil, fmt.Errorf("summoner not found in match participants")
+	}
+
+	stats := &LaningStats{}
+
+	if opponent := findLaneOpponent(*player, participants); opponent != nil {
+		stats.OpponentChampion = opponent.ChampionName
+		opponentID := participantIDs[opponent.PUUID]
+		stats.At10 = t.laningDiff(laningMinuteEarly, playerID, opponentID)
+		stats.At15 = t.laningDiff(laningMinuteLate, playerID, opponentID)
+	}
+
+	t.addEventStats(stats, playerID, player.TeamID, teams)
+
+	return stats, nil
+}
+
+// findLaneOpponent returns the player of the other team playing the same position, or nil.
+func findLaneOpponent(player MatchParticipant, participants []MatchParticipant) *MatchParticipant {
+	if player.TeamPosition == "" {
+		return nil
+	}
+
+	for i, p := range participants {
+		if p.TeamID != player.TeamID && p.TeamPosition == player.TeamPosition {
+			return &participants[i]
+		}
+	}
+
+	return nil
+}
+
+// laningDiff returns the differences between two participants at the frame of a given minute,
+// or nil if the game ended before it.
+func (t *MatchTimeline) laningDiff(minute, playerID, opponentID int) *LaningDiff {
+	if minute >= len(t.Info.Frames) {
+		return nil
+	}
+
+	frame := t.Info.Frames[minute]
+	player, ok := frame.ParticipantFrames[fmt.Sprint(playerID)]
+	if !ok {
+		return nil
+	}
+	opponent, ok := frame.ParticipantFrames[fmt.Sprint(opponentID)]
+	if !ok {
+		return nil
+	}
+
+	return &LaningDiff{
+		Gold: player.TotalGold - opponent.TotalGold,
+		CS:   (player.MinionsKilled + player.JungleMinionsKilled) - (opponent.MinionsKilled + opponent.JungleMinionsKilled),
+		XP:   player.XP - opponent.XP,
+	}
+}
+
+// addEventStats fills the first blood and objective stats of a participant from the events of the timeline.
+// teams maps participant ids to their team id.
+func (t *MatchTimeline) addEventStats(stats *LaningStats, playerID, teamID int, teams map[int]int) {
+	firstBloodSeen := false
+
+	for _, frame := range t.Info.Frames {
+		for _, event := range frame.Events {
+			involved := event.KillerID == playerID || slices.Contains(event.AssistingParticipantIDs, playerID)
+
+			switch event.Type {
+			case "CHAMPION_KILL":
+				if firstBloodSeen {
+					continue
+				}
+				firstBloodSeen = true
+				stats.FirstBloodKill = event.KillerID == playerID
+				stats.FirstBloodAssist = slices.Contains(event.AssistingParticipantIDs, playerID)
+				stats.FirstBloodVictim = event.VictimID == playerID
+			case "ELITE_MONSTER_KILL":
+				if event.KillerTeamID != teamID {
+					continue
+				}
+				stats.TeamObjectives++
+				if involved {
+					stats.ObjectivesParticipated++
+				}
+			case "BUILDING_KILL":
+				// teamId is the team that lost the building, the killer is 0 when minions took it
+				if event.TeamID == teamID || (event.KillerID != 0 && teams[event.KillerID] != teamID) {
+					continue
+				}
+				stats.TeamObjectives++
+				if involved {
+					stats.ObjectivesParticipated++
+				}
+			}
+		}
+	}
+}
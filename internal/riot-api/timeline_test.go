@@ -0,0 +1,115 @@
+package riotapi
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// readTestJSON decodes a match-v5 response of testdata into v.
+func readTestJSON(t *testing.T, file string, v any) {
+	t.Helper()
+
+	body, err := os.ReadFile("testdata/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		t.Fatalf("decoding %s: %v", file, err)
+	}
+}
+
+func TestLaningStats(t *testing.T) {
+	var match matchResponse
+	readTestJSON(t, "EUW1_7000000001.json", &match)
+	participants := createMatchParticipants(match.Info.Participants)
+
+	var timeline MatchTimeline
+	readTestJSON(t, "EUW1_7000000001_timeline.json", &timeline)
+
+	tests := []struct {
+		name  string
+		puuid string
+		// frames keeps the first frames of the timeline only, all of them when 0
+		frames int
+		want   *LaningStats
+	}{
+		{
+			name:  "mid laner who gave first blood",
+			puuid: "puuid-blue-middle",
+			want: &LaningStats{
+				OpponentChampion:       "Syndra",
+				At10:                   &LaningDiff{Gold: 500, CS: 10, XP: 300},
+				At15:                   &LaningDiff{Gold: 750, CS: 15, XP: 450},
+				FirstBloodVictim:       true,
+				TeamObjectives:         4,
+				ObjectivesParticipated: 2,
+			},
+		},
+		{
+			name:  "first blood killer",
+			puuid: "puuid-red-middle",
+			want: &LaningStats{
+				OpponentChampion:       "Ahri",
+				At10:                   &LaningDiff{Gold: -500, CS: -10, XP: -300},
+				At15:                   &LaningDiff{Gold: -750, CS: -15, XP: -450},
+				FirstBloodKill:         true,
+				TeamObjectives:         2,
+				ObjectivesParticipated: 1,
+			},
+		},
+		{
+			name:  "first blood assist, objectives of the red team",
+			puuid: "puuid-red-jungle",
+			want: &LaningStats{
+				OpponentChampion:       "LeeSin",
+				At10:                   &LaningDiff{},
+				At15:                   &LaningDiff{},
+				FirstBloodAssist:       true,
+				TeamObjectives:         2,
+				ObjectivesParticipated: 1,
+			},
+		},
+		{
+			name:  "no lane opponent",
+			puuid: "puuid-blue-utility",
+			want: &LaningStats{
+				TeamObjectives: 4,
+			},
+		},
+		{
+			name:   "game over before 15 minutes",
+			puuid:  "puuid-blue-middle",
+			frames: 12,
+			want: &LaningStats{
+				OpponentChampion:       "Syndra",
+				At10:                   &LaningDiff{Gold: 500, CS: 10, XP: 300},
+				FirstBloodVictim:       true,
+				TeamObjectives:         2,
+				ObjectivesParticipated: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := timeline
+			if tt.frames > 0 {
+				tl.Info.Frames = tl.Info.Frames[:tt.frames]
+			}
+
+			got, err := tl.LaningStats(tt.puuid, participants)
+			if err != nil {
+				t.Fatalf("LaningStats: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LaningStats(%s) = %+v, want %+v", tt.puuid, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := timeline.LaningStats("puuid-not-in-match", participants); err == nil {
+		t.Error("LaningStats of a player missing from the match: want an error")
+	}
+}
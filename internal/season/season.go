@@ -0,0 +1,154 @@
+// Package season tells which ranked split a date belongs to.
+package season
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// defaultResetMonth and defaultResetDay are when ranks are reset every year when no calendar is configured.
+// Riot also resets them between splits, on dates that change every year and have to be configured.
+const (
+	defaultResetMonth = time.January
+	defaultResetDay   = 10
+)
+
+// Split is a ranked split, ranks are reset at the start of each.
+type Split struct {
+	Year   int
+	Number int
+	Start  time.Time
+}
+
+// String returns the key a split is stored under, e.g. "S2025 S1".
+func (sp Split) String() string {
+	return fmt.Sprintf("S%d S%d", sp.Year, sp.Number)
+}
+
+// Calendar holds the start dates of ranked splits. A split lasts until the next one starts, the last configured
+// split lasts until a later one is added. Without configured splits, a single split a year is used.
+type Calendar struct {
+	splits []Split
+}
+
+// DefaultCalendar returns a calendar starting a single split on January 10 of every year.
+func DefaultCalendar() *Calendar {
+	return &Calendar{}
+}
+
+// NewCalendar returns a calendar of the given splits, in any order. Two splits can't start at the same time
+// or share a key.
+func NewCalendar(splits []Split) (*Calendar, error) {
+	sorted := make([]Split, len(splits))
+	copy(sorted, splits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	seen := make(map[string]bool)
+	for idx, split := range sorted {
+		if split.Number <= 0 {
+			return nil, fmt.Errorf("invalid split number %d for %d", split.Number, split.Year)
+		}
+		if seen[split.String()] {
+			return nil, fmt.Errorf("split %s is listed twice", split)
+		}
+		seen[split.String()] = true
+
+		if idx > 0 && split.Start.Equal(sorted[idx-1].Start) {
+			return nil, fmt.Errorf("splits %s and %s start at the same time", sorted[idx-1], split)
+		}
+	}
+
+	return &Calendar{splits: sorted}, nil
+}
+
+// calendarFileEntry is a split in a calendar file, start being a date ("2025-01-09") or an RFC 3339 time.
+type calendarFileEntry struct {
+	Year  int    `json:"year"`
+	Split int    `json:"split"`
+	Start string `json:"start"`
+}
+
+// LoadCalendar reads a calendar from a JSON file listing splits, e.g.
+//
+//	[{"year": 2025, "split": 1, "start": "2025-01-09"}, {"year": 2025, "split": 2, "start": "2025-04-30T12:00:00Z"}]
+//
+// Dates without a time start at midnight UTC.
+func LoadCalendar(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading season calendar %s: %w", path, err)
+	}
+
+	var entries []calendarFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing season calendar %s: %w", path, err)
+	}
+
+	splits := make([]Split, 0, len(entries))
+	for _, entry := range entries {
+		start, err := parseStart(entry.Start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of split %d of %d in %s: %w", entry.Split, entry.Year, path, err)
+		}
+		splits = append(splits, Split{Year: entry.Year, Number: entry.Split, Start: start})
+	}
+
+	calendar, err := NewCalendar(splits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid season calendar %s: %w", path, err)
+	}
+
+	return calendar, nil
+}
+
+// parseStart parses a date or an RFC 3339 time.
+func parseStart(value string) (time.Time, error) {
+	if start, err := time.Parse(time.DateOnly, value); err == nil {
+		return start, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// IsDefault reports whether the calendar uses the default yearly split.
+func (c *Calendar) IsDefault() bool {
+	return len(c.splits) == 0
+}
+
+// At returns the split a time belongs to. Times before the first configured split fall back to the default
+// yearly split.
+func (c *Calendar) At(t time.Time) Split {
+	idx := sort.Search(len(c.splits), func(i int) bool {
+		return c.splits[i].Start.After(t)
+	})
+	if idx > 0 {
+		return c.splits[idx-1]
+	}
+
+	return defaultSplitAt(t)
+}
+
+// Current returns the split in progress.
+func (c *Calendar) Current() Split {
+	return c.At(time.Now())
+}
+
+// defaultSplitAt returns the default yearly split a time belongs to, the first split of its year.
+func defaultSplitAt(t time.Time) Split {
+	t = t.UTC()
+	year := t.Year()
+
+	start := time.Date(year, defaultResetMonth, defaultResetDay, 0, 0, 0, 0, time.UTC)
+	if t.Before(start) {
+		// before the reset of the year, the split of the previous year is still running
+		year--
+		start = time.Date(year, defaultResetMonth, defaultResetDay, 0, 0, 0, 0, time.UTC)
+	}
+
+	return Split{Year: year, Number: 1, Start: start}
+}
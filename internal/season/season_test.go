@@ -0,0 +1,155 @@
+package season
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDefaultSplitAt(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Time
+		want Split
+	}{
+		{
+			name: "middle of the year",
+			at:   date(2025, time.July, 14),
+			want: Split{Year: 2025, Number: 1, Start: date(2025, time.January, 10)},
+		},
+		{
+			name: "reset day",
+			at:   date(2025, time.January, 10),
+			want: Split{Year: 2025, Number: 1, Start: date(2025, time.January, 10)},
+		},
+		{
+			name: "before the reset of the year",
+			at:   date(2025, time.January, 9).Add(23 * time.Hour),
+			want: Split{Year: 2024, Number: 1, Start: date(2024, time.January, 10)},
+		},
+		{
+			name: "new year's eve",
+			at:   date(2025, time.December, 31),
+			want: Split{Year: 2025, Number: 1, Start: date(2025, time.January, 10)},
+		},
+		{
+			name: "other time zone",
+			at:   time.Date(2025, time.January, 10, 0, 30, 0, 0, time.FixedZone("UTC+1", 3600)),
+			want: Split{Year: 2024, Number: 1, Start: date(2024, time.January, 10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultCalendar().At(tt.at)
+			if got.Year != tt.want.Year || got.Number != tt.want.Number || !got.Start.Equal(tt.want.Start) {
+				t.Errorf("At(%s) = %+v, want %+v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarAt(t *testing.T) {
+	calendar, err := NewCalendar([]Split{
+		{Year: 2025, Number: 2, Start: date(2025, time.April, 30)},
+		{Year: 2025, Number: 1, Start: date(2025, time.January, 9)},
+		{Year: 2025, Number: 3, Start: date(2025, time.August, 27)},
+	})
+	if err != nil {
+		t.Fatalf("NewCalendar: %v", err)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{at: date(2025, time.January, 9), want: "S2025 S1"},
+		{at: date(2025, time.April, 29), want: "S2025 S1"},
+		{at: date(2025, time.April, 30), want: "S2025 S2"},
+		{at: date(2025, time.September, 1), want: "S2025 S3"},
+		// the last split lasts until a later one is configured
+		{at: date(2026, time.March, 1), want: "S2025 S3"},
+		// times before the first configured split fall back to the default yearly split
+		{at: date(2024, time.June, 1), want: "S2024 S1"},
+		{at: date(2025, time.January, 8), want: "S2024 S1"},
+	}
+
+	for _, tt := range tests {
+		if got := calendar.At(tt.at).String(); got != tt.want {
+			t.Errorf("At(%s) = %s, want %s", tt.at.Format(time.DateOnly), got, tt.want)
+		}
+	}
+
+	if calendar.IsDefault() {
+		t.Error("IsDefault() = true for a configured calendar")
+	}
+	if !DefaultCalendar().IsDefault() {
+		t.Error("IsDefault() = false for the default calendar")
+	}
+}
+
+func TestNewCalendarInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		splits []Split
+	}{
+		{
+			name:   "split number 0",
+			splits: []Split{{Year: 2025, Number: 0, Start: date(2025, time.January, 9)}},
+		},
+		{
+			name: "same split twice",
+			splits: []Split{
+				{Year: 2025, Number: 1, Start: date(2025, time.January, 9)},
+				{Year: 2025, Number: 1, Start: date(2025, time.April, 30)},
+			},
+		},
+		{
+			name: "same start",
+			splits: []Split{
+				{Year: 2025, Number: 1, Start: date(2025, time.January, 9)},
+				{Year: 2025, Number: 2, Start: date(2025, time.January, 9)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCalendar(tt.splits); err == nil {
+				t.Error("NewCalendar: want an error")
+			}
+		})
+	}
+}
+
+func TestLoadCalendar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	content := `[{"year": 2025, "split": 1, "start": "2025-01-09"}, {"year": 2025, "split": 2, "start": "2025-04-30T12:00:00Z"}]`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	calendar, err := LoadCalendar(path)
+	if err != nil {
+		t.Fatalf("LoadCalendar: %v", err)
+	}
+
+	if got := calendar.At(time.Date(2025, time.April, 30, 11, 59, 0, 0, time.UTC)).String(); got != "S2025 S1" {
+		t.Errorf("At(2025-04-30 11:59) = %s, want S2025 S1", got)
+	}
+	if got := calendar.At(time.Date(2025, time.April, 30, 12, 0, 0, 0, time.UTC)).String(); got != "S2025 S2" {
+		t.Errorf("At(2025-04-30 12:00) = %s, want S2025 S2", got)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"year": 2025, "split": 1, "start": "January 9"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCalendar(path); err == nil {
+		t.Error("LoadCalendar with an invalid start: want an error")
+	}
+}
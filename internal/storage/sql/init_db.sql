@@ -8,6 +8,9 @@ CREATE TABLE IF NOT EXISTS guilds (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- queue ids (420 solo/duo, 440 flex, 450 ARAM...) announced in the guild
+ALTER TABLE guilds ADD COLUMN IF NOT EXISTS tracked_queues INTEGER[] NOT NULL DEFAULT '{420}';
+
 CREATE TABLE IF NOT EXISTS summoners (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name TEXT UNIQUE NOT NULL,
@@ -21,6 +24,18 @@ CREATE TABLE IF NOT EXISTS summoners (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- platform routing value (euw1, na1, kr...) the summoner plays on,
+-- rows created before multi-region support are backfilled with RIOT_REGION on startup
+ALTER TABLE summoners ADD COLUMN IF NOT EXISTS region TEXT;
+
+-- polling schedule of match tracking: when the summoner was last seen playing and when to check them next,
+-- NULL until their first check
+ALTER TABLE summoners ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE summoners ADD COLUMN IF NOT EXISTS next_check_at TIMESTAMP WITH TIME ZONE;
+
+-- when the Riot ID of the summoner was last checked with account-v1, NULL until their first check
+ALTER TABLE summoners ADD COLUMN IF NOT EXISTS name_checked_at TIMESTAMP WITH TIME ZONE;
+
 CREATE TABLE IF NOT EXISTS league_entries (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     summoner_id UUID REFERENCES summoners(id),
@@ -39,6 +54,9 @@ CREATE TABLE IF NOT EXISTS league_entries (
     UNIQUE(summoner_id, queue_type)
 );
 
+-- ranked games (wins and losses) Riot counted when the rank was stored, NULL for ranks stored before it was kept
+ALTER TABLE league_entries ADD COLUMN IF NOT EXISTS games INTEGER;
+
 CREATE TABLE IF NOT EXISTS matches (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     summoner_id UUID REFERENCES summoners(id),
@@ -70,6 +88,53 @@ CREATE TABLE IF NOT EXISTS matches (
     UNIQUE(summoner_id, match_id)
 );
 
+-- every player of the matches stored in matches, shared by the tracked summoners who played the same match
+CREATE TABLE IF NOT EXISTS match_participants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    match_id TEXT NOT NULL,
+    puuid TEXT NOT NULL,
+    riot_id_game_name TEXT,
+    riot_id_tag_line TEXT,
+    champion_id INTEGER NOT NULL,
+    champion_name TEXT NOT NULL,
+    team_id INTEGER NOT NULL,
+    team_position TEXT NOT NULL,
+    kills INTEGER NOT NULL,
+    deaths INTEGER NOT NULL,
+    assists INTEGER NOT NULL,
+    total_damage_dealt_to_champions INTEGER NOT NULL,
+    gold_earned INTEGER NOT NULL,
+    total_minions_and_neutral_minions_killed INTEGER NOT NULL,
+    items INTEGER[] NOT NULL,
+    win BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(match_id, puuid)
+);
+
+CREATE INDEX IF NOT EXISTS match_participants_puuid_idx ON match_participants (puuid);
+
+-- stats computed from the timeline of a match, next to the matches row of the tracked summoner,
+-- differences are NULL when there was no lane opponent or the game ended before that minute
+CREATE TABLE IF NOT EXISTS match_laning_stats (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    summoner_id UUID REFERENCES summoners(id),
+    match_id TEXT NOT NULL,
+    opponent_champion TEXT,
+    gold_diff_10 INTEGER,
+    cs_diff_10 INTEGER,
+    xp_diff_10 INTEGER,
+    gold_diff_15 INTEGER,
+    cs_diff_15 INTEGER,
+    xp_diff_15 INTEGER,
+    first_blood_kill BOOLEAN NOT NULL,
+    first_blood_assist BOOLEAN NOT NULL,
+    first_blood_victim BOOLEAN NOT NULL,
+    team_objectives INTEGER NOT NULL,
+    objectives_participated INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(summoner_id, match_id)
+);
+
 CREATE TABLE IF NOT EXISTS lp_history (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     summoner_id UUID REFERENCES summoners(id),
@@ -81,6 +146,35 @@ CREATE TABLE IF NOT EXISTS lp_history (
     timestamp TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
+-- league-v4 queue type the LP belongs to, rows created before flex support are solo/duo ones
+ALTER TABLE lp_history ADD COLUMN IF NOT EXISTS queue_type TEXT NOT NULL DEFAULT 'RANKED_SOLO_5x5';
+
+-- rank changes that happened without any match, event_type being dodge, decay, ladder, season_reset or correction
+CREATE TABLE IF NOT EXISTS rank_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    summoner_id UUID REFERENCES summoners(id),
+    queue_type TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    lp_change INTEGER NOT NULL,
+    previous_tier TEXT,
+    previous_rank TEXT,
+    previous_lp INTEGER,
+    new_tier TEXT NOT NULL,
+    new_rank TEXT NOT NULL,
+    new_lp INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- rank changes used to be stored in lp_history with a "DODGE" match id, their previous rank is unknown
+WITH moved AS (
+    DELETE FROM lp_history WHERE match_id = 'DODGE'
+    RETURNING summoner_id, queue_type, lp_change, tier, rank, new_lp, timestamp
+)
+INSERT INTO rank_events (summoner_id, queue_type, event_type, lp_change, new_tier, new_rank, new_lp, created_at)
+SELECT summoner_id, queue_type, CASE WHEN lp_change IN (-5, -15) THEN 'dodge' ELSE 'correction' END,
+    lp_change, tier, rank, new_lp, timestamp
+FROM moved;
+
 CREATE TABLE IF NOT EXISTS guild_summoner_associations (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     guild_id TEXT REFERENCES guilds(guild_id),
@@ -90,6 +184,9 @@ CREATE TABLE IF NOT EXISTS guild_summoner_associations (
     UNIQUE(guild_id, summoner_id)
 );
 
+-- queue ids announced for this summoner in the guild, NULL to follow the queues of the guild
+ALTER TABLE guild_summoner_associations ADD COLUMN IF NOT EXISTS tracked_queues INTEGER[];
+
 CREATE TABLE IF NOT EXISTS placement_games (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     summoner_id UUID REFERENCES summoners(id),
@@ -101,4 +198,132 @@ CREATE TABLE IF NOT EXISTS placement_games (
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(summoner_id, season),
     CONSTRAINT max_games CHECK (total_games <= 5)
-);
\ No newline at end of file
+);
+
+-- placements are played per ranked queue, rows created before flex support are solo/duo ones
+ALTER TABLE placement_games ADD COLUMN IF NOT EXISTS queue_type TEXT NOT NULL DEFAULT 'RANKED_SOLO_5x5';
+ALTER TABLE placement_games DROP CONSTRAINT IF EXISTS placement_games_summoner_id_season_key;
+CREATE UNIQUE INDEX IF NOT EXISTS placement_games_summoner_season_queue_idx ON placement_games (summoner_id, season, queue_type);
+
+CREATE TABLE IF NOT EXISTS live_game_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    guild_id TEXT REFERENCES guilds(guild_id),
+    match_id TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(guild_id, match_id)
+);
+
+-- last known mastery of every tracked summoner on each champion they played
+CREATE TABLE IF NOT EXISTS champion_masteries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    summoner_id UUID REFERENCES summoners(id),
+    champion_id INTEGER NOT NULL,
+    champion_level INTEGER NOT NULL,
+    champion_points INTEGER NOT NULL,
+    last_play_time BIGINT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(summoner_id, champion_id)
+);
+
+-- Riot status incidents and maintenances already posted in a guild
+CREATE TABLE IF NOT EXISTS status_announcements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    guild_id TEXT REFERENCES guilds(guild_id),
+    incident_id BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(guild_id, incident_id)
+);
+
+-- incidents and maintenance windows are numbered separately, rows created before are incident ones
+ALTER TABLE status_announcements ADD COLUMN IF NOT EXISTS status_type TEXT NOT NULL DEFAULT 'incident';
+ALTER TABLE status_announcements DROP CONSTRAINT IF EXISTS status_announcements_guild_id_incident_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS status_announcements_guild_type_incident_idx ON status_announcements (guild_id, status_type, incident_id);
+
+-- leases held by a bot instance, e.g. the one running the trackers when several instances share the database
+CREATE TABLE IF NOT EXISTS leases (
+    name TEXT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- highest rank reached by a summoner in a queue during a season (see season.Split)
+CREATE TABLE IF NOT EXISTS season_peaks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    summoner_id UUID REFERENCES summoners(id),
+    queue_type TEXT NOT NULL,
+    season TEXT NOT NULL,
+    tier TEXT NOT NULL,
+    rank TEXT NOT NULL,
+    league_points INTEGER NOT NULL,
+    reached_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(summoner_id, queue_type, season)
+);
+
+-- orders ranks by tier, then division, then LP, like utils.CompareRanks, to keep the highest season peak
+CREATE OR REPLACE FUNCTION league_rank_value(tier TEXT, division TEXT, league_points INTEGER) RETURNS INTEGER AS $$
+    SELECT (CASE UPPER(tier)
+        WHEN 'IRON' THEN 0 WHEN 'BRONZE' THEN 1 WHEN 'SILVER' THEN 2 WHEN 'GOLD' THEN 3 WHEN 'PLATINUM' THEN 4
+        WHEN 'EMERALD' THEN 5 WHEN 'DIAMOND' THEN 6 WHEN 'MASTER' THEN 7 WHEN 'GRANDMASTER' THEN 8 WHEN 'CHALLENGER' THEN 9
+        ELSE -1 END) * 100000
+        + (CASE UPPER(division) WHEN 'I' THEN 4 WHEN 'II' THEN 3 WHEN 'III' THEN 2 WHEN 'IV' THEN 1 ELSE 0 END) * 10000
+        + league_points
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Riot IDs a summoner had before their current one, changed_at being when they stopped using it
+CREATE TABLE IF NOT EXISTS summoner_name_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    summoner_id UUID REFERENCES summoners(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(summoner_id, name)
+);
+
+-- start of every ranked split, used as the season calendar when SEASON_CALENDAR_FILE isn't set
+CREATE TABLE IF NOT EXISTS season_splits (
+    year INTEGER NOT NULL,
+    split INTEGER NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (year, split)
+);
+
+-- splits the bot saw start, the latest being the one in progress for the bot
+CREATE TABLE IF NOT EXISTS season_rollovers (
+    season TEXT PRIMARY KEY,
+    rolled_over_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- final rank of a summoner in a queue at the end of a split, with their peak of the split
+CREATE TABLE IF NOT EXISTS season_results (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    summoner_id UUID REFERENCES summoners(id),
+    season TEXT NOT NULL,
+    queue_type TEXT NOT NULL,
+    tier TEXT NOT NULL,
+    rank TEXT NOT NULL,
+    league_points INTEGER NOT NULL,
+    peak_tier TEXT,
+    peak_rank TEXT,
+    peak_league_points INTEGER,
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(summoner_id, season, queue_type)
+);
+
+-- match results posted in a guild, one row per tracked summoner of the match, so none is posted twice
+-- when the tracker lease moves to another instance during a check
+CREATE TABLE IF NOT EXISTS match_announcements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    guild_id TEXT REFERENCES guilds(guild_id),
+    match_id TEXT NOT NULL,
+    summoner_puuid TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(guild_id, match_id, summoner_puuid)
+);
+
+-- Riot API key set with /apikey, a single row read by every instance
+CREATE TABLE IF NOT EXISTS riot_api_key (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    api_key TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
@@ -15,11 +15,12 @@ const (
 	insertSummonerSQL SQLQuery = `
     INSERT INTO summoners (
         name, riot_account_id, riot_summoner_id, riot_summoner_puuid, summoner_level, profile_icon_id, 
-        revision_date, created_at, updated_at
+        revision_date, region, created_at, updated_at
     ) 
-    VALUES ($1, $2, $3, $4, $5, $6, $7::BIGINT, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) 
+    VALUES ($1, $2, $3, $4, $5, $6, $7::BIGINT, $8, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) 
     ON CONFLICT (riot_summoner_id) DO UPDATE SET 
         name = EXCLUDED.name,
+        region = EXCLUDED.region,
         summoner_level = EXCLUDED.summoner_level,
         profile_icon_id = EXCLUDED.profile_icon_id,
         revision_date = EXCLUDED.revision_date,
@@ -31,16 +32,17 @@ const (
 	insertLeagueEntrySQL SQLQuery = `
     INSERT INTO league_entries (
         summoner_id, queue_type, tier, rank, league_points,
-        wins, losses, hot_streak, veteran, fresh_blood, inactive,
+        wins, losses, games, hot_streak, veteran, fresh_blood, inactive,
         created_at, updated_at
     )
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $6::integer + $7::integer, $8, $9, $10, $11, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
     ON CONFLICT (summoner_id, queue_type) DO UPDATE SET
         tier = EXCLUDED.tier,
         rank = EXCLUDED.rank,
         league_points = EXCLUDED.league_points,
         wins = EXCLUDED.wins,
         losses = EXCLUDED.losses,
+        games = EXCLUDED.games,
         hot_streak = EXCLUDED.hot_streak,
         veteran = EXCLUDED.veteran,
         fresh_blood = EXCLUDED.fresh_blood,
@@ -73,6 +75,38 @@ const (
             $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15,
             $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
     ) ON CONFLICT (summoner_id, match_id) DO NOTHING
+    `
+
+	// insert a player of a match into match_participants
+	insertMatchParticipantSQL SQLQuery = `
+    INSERT INTO match_participants (
+            match_id, puuid, riot_id_game_name, riot_id_tag_line, champion_id, champion_name,
+            team_id, team_position, kills, deaths, assists, total_damage_dealt_to_champions,
+            gold_earned, total_minions_and_neutral_minions_killed, items, win
+    ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+    ) ON CONFLICT (match_id, puuid) DO NOTHING
+    `
+
+	// insert the timeline stats of a match for a summoner
+	insertMatchLaningStatsSQL SQLQuery = `
+    INSERT INTO match_laning_stats (
+            summoner_id, match_id, opponent_champion,
+            gold_diff_10, cs_diff_10, xp_diff_10, gold_diff_15, cs_diff_15, xp_diff_15,
+            first_blood_kill, first_blood_assist, first_blood_victim, team_objectives, objectives_participated
+    ) VALUES (
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+    ) ON CONFLICT (summoner_id, match_id) DO NOTHING
+    `
+
+	// get every player of a match
+	selectMatchParticipantsSQL SQLQuery = `
+    SELECT puuid, riot_id_game_name, riot_id_tag_line, champion_id, champion_name,
+            team_id, team_position, kills, deaths, assists, total_damage_dealt_to_champions,
+            gold_earned, total_minions_and_neutral_minions_killed, items, win
+    FROM match_participants
+    WHERE match_id = $1
+    ORDER BY team_id
     `
 
 	// gives the rank from a summoner by joining summoners and league entries
@@ -84,22 +118,37 @@ const (
         s.profile_icon_id, 
         s.revision_date, 
         s.summoner_level, 
+        s.region,
         s.name,
         CASE
             WHEN le.tier = 'UNRANKED' OR le.tier IS NULL THEN 'UNRANKED'
             ELSE le.tier || ' ' || le.rank
         END as rank,
-        COALESCE(le.league_points, 0) as league_points
+        COALESCE(le.league_points, 0) as league_points,
+        CASE
+            WHEN flex.tier = 'UNRANKED' OR flex.tier IS NULL THEN 'UNRANKED'
+            ELSE flex.tier || ' ' || flex.rank
+        END as flex_rank,
+        COALESCE(flex.league_points, 0) as flex_league_points
     FROM 
         summoners s
     LEFT JOIN 
         league_entries le ON s.id = le.summoner_id AND le.queue_type = 'RANKED_SOLO_5x5'
+    LEFT JOIN 
+        league_entries flex ON s.id = flex.summoner_id AND flex.queue_type = 'RANKED_FLEX_SR'
     JOIN 
         guild_summoner_associations gsa ON s.id = gsa.summoner_id
     WHERE 
         gsa.guild_id = $1
     `
 
+	// set the region of summoners added before multi-region support
+	backfillSummonerRegionSQL SQLQuery = `
+    UPDATE summoners
+    SET region = $1
+    WHERE region IS NULL
+    `
+
 	// get the channel id from guilds table
 	selectChannelIdFromGuildIdSQL SQLQuery = `
     SELECT channel_id
@@ -121,40 +170,42 @@ const (
     WHERE guild_id = $1
     `
 
-	// get last match id from db
-	selectLastMatchIDSQL SQLQuery = `
-    SELECT match_id
+	// get last match id of a queue and its creation timestamp from db
+	selectLastMatchSQL SQLQuery = `
+    SELECT match_id, game_creation
     FROM matches
-    WHERE summoner_id = (SELECT id FROM summoners WHERE riot_summoner_puuid = $1)
+    WHERE summoner_id = (SELECT id FROM summoners WHERE riot_summoner_puuid = $1) AND queue_id = $2
     ORDER BY game_end_timestamp DESC
     LIMIT 1
 	`
 
-	// update LP, rank and tier in league entries
+	// update LP, rank and tier of a queue in league entries, creating the entry of queues the summoner
+	// wasn't placed in yet
 	updateLeagueEntriesSQL SQLQuery = `
-    UPDATE league_entries
-    SET 
-        league_points = $1, 
-        tier = $2, 
-        rank = $3, 
+    INSERT INTO league_entries (summoner_id, queue_type, league_points, tier, rank, wins, losses, games, created_at, updated_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $6::integer + $7::integer, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+    ON CONFLICT (summoner_id, queue_type) DO UPDATE SET
+        league_points = EXCLUDED.league_points,
+        tier = EXCLUDED.tier,
+        rank = EXCLUDED.rank,
+        wins = EXCLUDED.wins,
+        losses = EXCLUDED.losses,
+        games = EXCLUDED.games,
         updated_at = CURRENT_TIMESTAMP
-    WHERE 
-        summoner_id = $4 
-        AND queue_type = 'RANKED_SOLO_5x5'
     `
 
 	// create a new row for lp, tier and rank in lp_history with a summoner id
 	// we store the tier and rank for a better tracking of progress
 	insertLDataInLPHistorySQL SQLQuery = `
-    INSERT INTO lp_history (summoner_id, match_id, lp_change, new_lp, tier, rank)
-    VALUES ($1, $2, $3, $4, $5, $6)
+    INSERT INTO lp_history (summoner_id, match_id, lp_change, new_lp, tier, rank, queue_type)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
 
-	// get rank from league entries
+	// get rank of a queue from league entries
 	selectRankInLeagueEntriesSQL SQLQuery = `
-    SELECT tier, rank, league_points
+    SELECT tier, rank, league_points, COALESCE(games, 0)
     FROM league_entries
-    WHERE summoner_id = $1
+    WHERE summoner_id = $1 AND queue_type = $2
     `
 
 	// remove all summoners associated to a guild
@@ -163,12 +214,303 @@ const (
     WHERE guild_id = $1
 	`
 
+	// get every tracked summoner, once per guild tracking them, with the queues tracked for them in that guild
 	selectSummonerInGuildSQL SQLQuery = `
-    SELECT s.riot_summoner_id, s.riot_account_id, s.riot_summoner_puuid, 
-            s.profile_icon_id, s.revision_date, s.summoner_level, s.name,
-            array_agg(gsa.guild_id) as guild_ids
+    SELECT s.id, s.riot_summoner_id, s.riot_account_id, s.riot_summoner_puuid, 
+            s.profile_icon_id, s.revision_date, s.summoner_level, s.region, s.name, s.last_active_at, s.name_checked_at,
+            gsa.guild_id, COALESCE(gsa.tracked_queues, g.tracked_queues) as tracked_queues
+    FROM summoners s
+    JOIN guild_summoner_associations gsa ON s.id = gsa.summoner_id
+    JOIN guilds g ON g.guild_id = gsa.guild_id
+    ORDER BY s.id
+    `
+
+	// get the tracked summoners due for a check, like selectSummonerInGuildSQL
+	selectDueSummonerInGuildSQL SQLQuery = `
+    SELECT s.id, s.riot_summoner_id, s.riot_account_id, s.riot_summoner_puuid, 
+            s.profile_icon_id, s.revision_date, s.summoner_level, s.region, s.name, s.last_active_at, s.name_checked_at,
+            gsa.guild_id, COALESCE(gsa.tracked_queues, g.tracked_queues) as tracked_queues
     FROM summoners s
     JOIN guild_summoner_associations gsa ON s.id = gsa.summoner_id
-    GROUP BY s.id
+    JOIN guilds g ON g.guild_id = gsa.guild_id
+    WHERE s.next_check_at IS NULL OR s.next_check_at <= $1
+    ORDER BY s.id
+    `
+
+	// store the outcome of a check of a summoner: the revision date seen and when to check them next
+	updateSummonerScheduleSQL SQLQuery = `
+    UPDATE summoners
+    SET revision_date = $2, last_active_at = $3, next_check_at = $4
+    WHERE id = $1
+    `
+
+	// mark the summoners of the PUUIDs $1 as active right now, so match tracking checks them at its next tick
+	updateSummonersActiveSQL SQLQuery = `
+    UPDATE summoners
+    SET last_active_at = CURRENT_TIMESTAMP, next_check_at = CURRENT_TIMESTAMP
+    WHERE riot_summoner_puuid = ANY($1)
+    `
+
+	// get the queues tracked in a guild
+	selectGuildTrackedQueuesSQL SQLQuery = `
+    SELECT tracked_queues
+    FROM guilds
+    WHERE guild_id = $1
+    `
+
+	// set the queues tracked in a guild
+	updateGuildTrackedQueuesSQL SQLQuery = `
+    UPDATE guilds
+    SET tracked_queues = $2, updated_at = CURRENT_TIMESTAMP
+    WHERE guild_id = $1
+    `
+
+	// set the queues tracked for a summoner in a guild, NULL to follow the queues of the guild
+	updateSummonerTrackedQueuesSQL SQLQuery = `
+    UPDATE guild_summoner_associations
+    SET tracked_queues = $3, updated_at = CURRENT_TIMESTAMP
+    WHERE guild_id = $1 AND summoner_id = (SELECT id FROM summoners WHERE LOWER(name) = LOWER($2))
+    `
+
+	// remember the message announcing a live game in a guild
+	insertLiveGameMessageSQL SQLQuery = `
+    INSERT INTO live_game_messages (guild_id, match_id, channel_id, message_id)
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT (guild_id, match_id) DO NOTHING
+    `
+
+	// get the message announcing a live game in a guild
+	selectLiveGameMessageSQL SQLQuery = `
+    SELECT channel_id, message_id
+    FROM live_game_messages
+    WHERE guild_id = $1 AND match_id = $2
+    `
+
+	// forget live game messages of games that are long over
+	deleteStaleLiveGameMessagesSQL SQLQuery = `
+    DELETE FROM live_game_messages
+    WHERE created_at < $1
+    `
+
+	// get every guild with a channel set for updates
+	selectGuildsWithChannelSQL SQLQuery = `
+    SELECT guild_id, guild_name, channel_id
+    FROM guilds
+    WHERE channel_id IS NOT NULL AND channel_id != ''
+    `
+
+	// get the guilds with an update channel tracking a summoner of a region
+	selectGuildsWithChannelInRegionSQL SQLQuery = `
+    SELECT DISTINCT g.guild_id, g.guild_name, g.channel_id
+    FROM guilds g
+    JOIN guild_summoner_associations gsa ON gsa.guild_id = g.guild_id
+    JOIN summoners s ON s.id = gsa.summoner_id
+    WHERE g.channel_id IS NOT NULL AND g.channel_id != '' AND s.region = $1
+    `
+
+	// get the regions of the tracked summoners
+	selectTrackedRegionsSQL SQLQuery = `
+    SELECT DISTINCT region
+    FROM summoners
+    WHERE region IS NOT NULL
+    ORDER BY region
+    `
+
+	// check whether a Riot status incident or maintenance was already posted in a guild
+	selectStatusAnnouncementSQL SQLQuery = `
+    SELECT EXISTS(SELECT 1 FROM status_announcements WHERE guild_id = $1 AND status_type = $2 AND incident_id = $3)
+    `
+
+	// remember that a Riot status incident or maintenance was posted in a guild
+	insertStatusAnnouncementSQL SQLQuery = `
+    INSERT INTO status_announcements (guild_id, status_type, incident_id)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (guild_id, status_type, incident_id) DO NOTHING
+    `
+
+	// get the last known mastery of a summoner on every champion
+	selectChampionMasteriesSQL SQLQuery = `
+    SELECT champion_id, champion_level, champion_points, last_play_time
+    FROM champion_masteries
+    WHERE summoner_id = $1
+    `
+
+	// insert or update the mastery of a summoner on a champion
+	upsertChampionMasterySQL SQLQuery = `
+    INSERT INTO champion_masteries (summoner_id, champion_id, champion_level, champion_points, last_play_time)
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT (summoner_id, champion_id) DO UPDATE
+    SET champion_level = EXCLUDED.champion_level,
+        champion_points = EXCLUDED.champion_points,
+        last_play_time = EXCLUDED.last_play_time,
+        updated_at = CURRENT_TIMESTAMP
+    `
+
+	// take or renew a lease for $3 seconds, unless another holder has it and it didn't expire
+	acquireLeaseSQL SQLQuery = `
+    INSERT INTO leases (name, holder, expires_at)
+    VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')
+    ON CONFLICT (name) DO UPDATE
+    SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+    WHERE leases.holder = EXCLUDED.holder OR leases.expires_at < CURRENT_TIMESTAMP
+    RETURNING holder
+    `
+
+	// give up a lease, if still held by $2
+	releaseLeaseSQL SQLQuery = `
+    DELETE FROM leases
+    WHERE name = $1 AND holder = $2
+    `
+
+	// record a rank change that happened without any match
+	insertRankEventSQL SQLQuery = `
+    INSERT INTO rank_events (summoner_id, queue_type, event_type, lp_change,
+        previous_tier, previous_rank, previous_lp, new_tier, new_rank, new_lp)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `
+
+	// get whether a summoner won their last matches of a queue lasting at least $3 seconds, newest first
+	selectRecentResultsSQL SQLQuery = `
+    SELECT win
+    FROM matches
+    WHERE summoner_id = $1 AND queue_id = $2 AND game_duration >= $3
+    ORDER BY game_creation DESC
+    LIMIT $4
+    `
+
+	// get the highest rank reached by a summoner in a queue during a season
+	// store a rank as the peak of a summoner in a queue during a season when it is higher than the stored one,
+	// and get the peak before it. The row is locked by the upsert, concurrent checks can't lower the peak.
+	upsertSeasonPeakSQL SQLQuery = `
+    WITH previous AS (
+        SELECT tier, rank, league_points
+        FROM season_peaks
+        WHERE summoner_id = $1 AND queue_type = $2 AND season = $3
+    ), upsert AS (
+        INSERT INTO season_peaks (summoner_id, queue_type, season, tier, rank, league_points)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (summoner_id, queue_type, season) DO UPDATE
+        SET tier = EXCLUDED.tier,
+            rank = EXCLUDED.rank,
+            league_points = EXCLUDED.league_points,
+            reached_at = CURRENT_TIMESTAMP
+        WHERE league_rank_value(EXCLUDED.tier, EXCLUDED.rank, EXCLUDED.league_points)
+            > league_rank_value(season_peaks.tier, season_peaks.rank, season_peaks.league_points)
+    )
+    SELECT tier, rank, league_points
+    FROM previous
+    `
+
+	// get the current name of a summoner, locking the row until the end of the transaction
+	selectSummonerNameForUpdateSQL SQLQuery = `
+    SELECT name
+    FROM summoners
+    WHERE id = $1
+    FOR UPDATE
+    `
+
+	// remember a Riot ID a summoner stopped using
+	insertSummonerNameHistorySQL SQLQuery = `
+    INSERT INTO summoner_name_history (summoner_id, name)
+    VALUES ($1, $2)
+    ON CONFLICT (summoner_id, name) DO UPDATE
+    SET changed_at = CURRENT_TIMESTAMP
+    `
+
+	// get the current name of the summoner tracked in a guild whose current or past Riot ID is $2,
+	// the current names being preferred over past ones
+	selectSummonerNameByAnyNameSQL SQLQuery = `
+    SELECT s.name
+    FROM summoners s
+    JOIN guild_summoner_associations gsa ON gsa.summoner_id = s.id AND gsa.guild_id = $1
+    LEFT JOIN summoner_name_history h ON h.summoner_id = s.id AND LOWER(h.name) = LOWER($2)
+    WHERE LOWER(s.name) = LOWER($2) OR h.id IS NOT NULL
+    ORDER BY LOWER(s.name) = LOWER($2) DESC, h.changed_at DESC NULLS LAST
+    LIMIT 1
+    `
+
+	// get the split start dates of the season calendar
+	selectSeasonSplitsSQL SQLQuery = `
+    SELECT year, split, starts_at
+    FROM season_splits
+    `
+
+	// get the last split the bot saw start
+	selectLastSeasonRolloverSQL SQLQuery = `
+    SELECT season
+    FROM season_rollovers
+    ORDER BY rolled_over_at DESC
+    LIMIT 1
+    `
+
+	// remember that a split started, nothing is inserted if another instance already did
+	insertSeasonRolloverSQL SQLQuery = `
+    INSERT INTO season_rollovers (season)
+    VALUES ($1)
+    ON CONFLICT (season) DO NOTHING
+    `
+
+	// snapshot the ranks of every tracked summoner as their final ranks of the split $1
+	insertSeasonResultsSQL SQLQuery = `
+    INSERT INTO season_results (summoner_id, season, queue_type, tier, rank, league_points, peak_tier, peak_rank, peak_league_points)
+    SELECT le.summoner_id, $1, le.queue_type, le.tier, COALESCE(le.rank, ''), COALESCE(le.league_points, 0), sp.tier, sp.rank, sp.league_points
+    FROM league_entries le
+    LEFT JOIN season_peaks sp ON sp.summoner_id = le.summoner_id AND sp.queue_type = le.queue_type AND sp.season = $1
+    WHERE le.tier IS NOT NULL AND UPPER(le.tier) != 'UNRANKED'
+    AND EXISTS (SELECT 1 FROM guild_summoner_associations gsa WHERE gsa.summoner_id = le.summoner_id)
+    ON CONFLICT (summoner_id, season, queue_type) DO NOTHING
+    `
+
+	// start the placements of the split $2 for every summoner ranked at the end of the split $1
+	insertSeasonPlacementsSQL SQLQuery = `
+    INSERT INTO placement_games (summoner_id, season, queue_type, total_games, wins, losses)
+    SELECT summoner_id, $2, queue_type, 0, 0, 0
+    FROM season_results
+    WHERE season = $1
+    ON CONFLICT (summoner_id, season, queue_type) DO NOTHING
+    `
+
+	// reset the stored ranks snapshotted for the split $1, their next games are placements
+	resetSeasonLeagueEntriesSQL SQLQuery = `
+    UPDATE league_entries
+    SET tier = 'UNRANKED', rank = '', league_points = 0, games = NULL, updated_at = CURRENT_TIMESTAMP
+    WHERE (summoner_id, queue_type) IN (SELECT summoner_id, queue_type FROM season_results WHERE season = $1)
+    `
+
+	// get the final ranks of the split $2 of the summoners tracked in a guild
+	selectGuildSeasonResultsSQL SQLQuery = `
+    SELECT s.name, r.queue_type, r.tier, r.rank, r.league_points,
+        COALESCE(r.peak_tier, ''), COALESCE(r.peak_rank, ''), COALESCE(r.peak_league_points, 0)
+    FROM season_results r
+    JOIN summoners s ON s.id = r.summoner_id
+    JOIN guild_summoner_associations gsa ON gsa.summoner_id = r.summoner_id AND gsa.guild_id = $1
+    WHERE r.season = $2
+    `
+
+	// claim the announcement of the result of a summoner in a match for a guild
+	insertMatchAnnouncementSQL SQLQuery = `
+    INSERT INTO match_announcements (guild_id, match_id, summoner_puuid)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (guild_id, match_id, summoner_puuid) DO NOTHING
+    `
+
+	// forget match announcements created before a given time
+	deleteStaleMatchAnnouncementsSQL SQLQuery = `
+    DELETE FROM match_announcements
+    WHERE created_at < $1
+    `
+
+	// store the Riot API key set with /apikey
+	upsertRiotAPIKeySQL SQLQuery = `
+    INSERT INTO riot_api_key (id, api_key, updated_at)
+    VALUES (TRUE, $1, CURRENT_TIMESTAMP)
+    ON CONFLICT (id) DO UPDATE
+    SET api_key = EXCLUDED.api_key, updated_at = EXCLUDED.updated_at
+    `
+
+	// get the Riot API key set with /apikey and when it was set
+	selectRiotAPIKeySQL SQLQuery = `
+    SELECT api_key, updated_at
+    FROM riot_api_key
     `
 )
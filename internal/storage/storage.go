@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	_ "embed"
 	"errors"
@@ -10,9 +11,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/tristan-derez/league-tracker/internal/config"
 	riotapi "github.com/tristan-derez/league-tracker/internal/riot-api"
+	"github.com/tristan-derez/league-tracker/internal/season"
 	"github.com/tristan-derez/league-tracker/internal/utils"
 )
 
@@ -22,6 +24,13 @@ var initDBSQL string
 // Storage represents a database connection and provides methods for data operations.
 type Storage struct {
 	db *sql.DB
+	// calendar tells the split placements, peaks and season results are stored under
+	calendar *season.Calendar
+}
+
+// execer runs a statement, within a transaction (*sql.Tx) or not (*sql.DB).
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
 // New creates and initializes a new Storage instance connected to the specified PostgreSQL database.
@@ -39,9 +48,62 @@ func New(config *config.Config) (*Storage, error) {
 		return nil, fmt.Errorf("error initializing database: %w", err)
 	}
 
+	if _, err := db.Exec(string(backfillSummonerRegionSQL), strings.ToLower(config.RiotAPIRegion)); err != nil {
+		return nil, fmt.Errorf("error backfilling summoner regions: %w", err)
+	}
+
+	calendar, err := storage.loadSeasonCalendar(config.SeasonCalendarFile)
+	if err != nil {
+		return nil, err
+	}
+	storage.calendar = calendar
+
 	return storage, nil
 }
 
+// loadSeasonCalendar reads the split calendar from path when set, else from the season_splits table.
+// The default yearly dates are used when neither lists any split.
+func (s *Storage) loadSeasonCalendar(path string) (*season.Calendar, error) {
+	if path != "" {
+		calendar, err := season.LoadCalendar(path)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Season calendar loaded from %s", path)
+		return calendar, nil
+	}
+
+	rows, err := s.db.Query(string(selectSeasonSplitsSQL))
+	if err != nil {
+		return nil, fmt.Errorf("error querying season splits: %w", err)
+	}
+	defer rows.Close()
+
+	var splits []season.Split
+	for rows.Next() {
+		var split season.Split
+		if err := rows.Scan(&split.Year, &split.Number, &split.Start); err != nil {
+			return nil, fmt.Errorf("error scanning season split: %w", err)
+		}
+		splits = append(splits, split)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(splits) == 0 {
+		return season.DefaultCalendar(), nil
+	}
+
+	calendar, err := season.NewCalendar(splits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid season_splits: %w", err)
+	}
+	log.Printf("Season calendar loaded from the database (%d splits)", len(splits))
+
+	return calendar, nil
+}
+
 // initDB initializes the database by executing the SQL query in initDBSQL.
 func (s *Storage) initDB() error {
 	_, err := s.db.Exec(initDBSQL)
@@ -66,9 +128,9 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
-// AddSummoner adds or updates a summoner's information, their league entry if available,
+// AddSummoner adds or updates a summoner's information, their league entries (one per ranked queue),
 // and associates them with a guild in the database.
-func (s *Storage) AddSummoner(guildID, channelID, summonerName string, summoner riotapi.Summoner, leagueEntry *riotapi.LeagueEntry) (uuid.UUID, error) {
+func (s *Storage) AddSummoner(guildID, channelID, summonerName string, summoner riotapi.Summoner, leagueEntries []*riotapi.LeagueEntry) (uuid.UUID, error) {
 	tx, err := s.db.Begin()
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("begin transaction: %w", err)
@@ -78,12 +140,12 @@ func (s *Storage) AddSummoner(guildID, channelID, summonerName string, summoner
 	var summonerUUID uuid.UUID
 	err = tx.QueryRow(string(insertSummonerSQL),
 		summonerName, summoner.RiotAccountID, summoner.RiotSummonerID, summoner.SummonerPUUID,
-		summoner.SummonerLevel, summoner.ProfileIconID, summoner.RevisionDate).Scan(&summonerUUID)
+		summoner.SummonerLevel, summoner.ProfileIconID, summoner.RevisionDate, summoner.Region).Scan(&summonerUUID)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("insert/update summoner: %w", err)
 	}
 
-	if leagueEntry != nil {
+	for _, leagueEntry := range leagueEntries {
 		_, err = tx.Exec(string(insertLeagueEntrySQL),
 			summonerUUID, leagueEntry.QueueType, leagueEntry.Tier, leagueEntry.Rank,
 			leagueEntry.LeaguePoints, leagueEntry.Wins, leagueEntry.Losses,
@@ -185,14 +247,14 @@ func (s *Storage) RemoveAllSummoners(guildID string) error {
 
 // AddMatchAndGetLPChange adds a new match record to the database for a given summoner,
 // updates lp_history and league_entry, and returns the LP change.
-func (s *Storage) AddMatchAndGetLPChange(riotSummonerID string, matchData *riotapi.MatchData, newLP int, newRank, newTier string) (int, error) {
-	tx, err := s.db.Begin()
+func (s *Storage) AddMatchAndGetLPChange(ctx context.Context, riotSummonerID string, matchData *riotapi.MatchData, entry *riotapi.LeagueEntry) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, fmt.Errorf("begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	summonerUUID, err := s.GetSummonerUUIDFromRiotID(riotSummonerID)
+	summonerUUID, err := s.GetSummonerUUIDFromRiotID(ctx, riotSummonerID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, fmt.Errorf("summoner with Riot ID %s not found", riotSummonerID)
@@ -200,24 +262,29 @@ func (s *Storage) AddMatchAndGetLPChange(riotSummonerID string, matchData *riota
 		return 0, fmt.Errorf("error fetching summoner UUID: %w", err)
 	}
 
-	err = s.insertMatchData(summonerUUID, matchData)
+	err = insertMatchData(ctx, tx, summonerUUID, matchData)
 	if err != nil {
 		return 0, fmt.Errorf("error inserting match data: %w", err)
 	}
 
-	previousRank, err := s.GetPreviousRank(summonerUUID)
+	queueType := riotapi.LeagueQueueType(matchData.QueueID)
+
+	previousRank, err := s.GetPreviousRank(ctx, summonerUUID, queueType)
 	if err != nil {
 		return 0, fmt.Errorf("error fetching previous rank: %w", err)
 	}
 
-	lpChange := s.CalculateLPChange(previousRank.PrevTier, newTier, previousRank.PrevRank, newRank, previousRank.PrevLP, newLP)
+	var lpChange int
+	if previousRank != nil {
+		lpChange = s.CalculateLPChange(previousRank.PrevTier, entry.Tier, previousRank.PrevRank, entry.Rank, previousRank.PrevLP, entry.LeaguePoints)
+	}
 
-	err = s.CreateNewRowInLPHistory(summonerUUID, matchData.MatchID, lpChange, newLP, newTier, newRank)
+	err = insertLPHistory(ctx, tx, summonerUUID, queueType, matchData.MatchID, lpChange, entry.LeaguePoints, entry.Tier, entry.Rank)
 	if err != nil {
 		return 0, err
 	}
 
-	err = s.UpdateLeagueEntry(summonerUUID, newLP, newTier, newRank)
+	err = updateLeagueEntry(ctx, tx, summonerUUID, entry)
 	if err != nil {
 		return 0, err
 	}
@@ -230,15 +297,15 @@ func (s *Storage) AddMatchAndGetLPChange(riotSummonerID string, matchData *riota
 	return lpChange, nil
 }
 
-// GetLeagueEntry retrieves the league entry (rank information) for a given summoner UUID.
-func (s *Storage) GetLeagueEntry(summonerUUID uuid.UUID) (*riotapi.LeagueEntry, error) {
+// GetLeagueEntry retrieves the league entry (rank information) of a queue type for a given summoner UUID.
+func (s *Storage) GetLeagueEntry(summonerUUID uuid.UUID, queueType string) (*riotapi.LeagueEntry, error) {
 	var leagueEntry riotapi.LeagueEntry
 
 	err := s.db.QueryRow(`
         SELECT queue_type, tier, rank, league_points, wins, losses, hot_streak, veteran, fresh_blood, inactive
         FROM league_entries
-        WHERE summoner_id = $1
-    `, summonerUUID).Scan(
+        WHERE summoner_id = $1 AND queue_type = $2
+    `, summonerUUID, queueType).Scan(
 		&leagueEntry.QueueType,
 		&leagueEntry.Tier,
 		&leagueEntry.Rank,
@@ -257,35 +324,35 @@ func (s *Storage) GetLeagueEntry(summonerUUID uuid.UUID) (*riotapi.LeagueEntry,
 	return &leagueEntry, nil
 }
 
-// InitializePlacementGames initializes the placement games record for a summoner
-func (s *Storage) InitializePlacementGames(summonerUUID uuid.UUID, season Season, status *riotapi.PlacementStatus) error {
-	seasonStr := SeasonToString(season)
+// InitializePlacementGames initializes the placement games record of a ranked queue type for a summoner
+func (s *Storage) InitializePlacementGames(ctx context.Context, summonerUUID uuid.UUID, split season.Split, queueType string, status *riotapi.PlacementStatus) error {
+	seasonStr := split.String()
 
-	_, err := s.db.Exec(`
-		INSERT INTO placement_games (summoner_id, season, total_games, wins, losses)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (summoner_id, season) DO UPDATE
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO placement_games (summoner_id, season, total_games, wins, losses, queue_type)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (summoner_id, season, queue_type) DO UPDATE
 		SET total_games = $3, wins = $4, losses = $5
-	`, summonerUUID, seasonStr, status.TotalGames, status.Wins, status.Losses)
+	`, summonerUUID, seasonStr, status.TotalGames, status.Wins, status.Losses, queueType)
 
 	return err
 }
 
-// IncrementPlacementGames increments the placement game stats for a summoner
-func (s *Storage) IncrementPlacementGames(summonerUUID uuid.UUID, isWin bool) error {
-	currentSeason := s.GetCurrentSeason()
-	seasonStr := SeasonToString(currentSeason)
+// IncrementPlacementGames increments the placement game stats of a ranked queue type for a summoner
+func (s *Storage) IncrementPlacementGames(ctx context.Context, summonerUUID uuid.UUID, queueType string, isWin bool) error {
+	seasonStr := s.GetCurrentSeason().String()
 
-	_, err := s.db.Exec(`
-		INSERT INTO placement_games (summoner_id, season, total_games, wins, losses)
-		VALUES ($1, $2, 1, CASE WHEN $3 THEN 1 ELSE 0 END, CASE WHEN $3 THEN 0 ELSE 1 END)
-		ON CONFLICT (summoner_id, season) DO UPDATE
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO placement_games (summoner_id, season, total_games, wins, losses, queue_type)
+		VALUES ($1, $2, 1, CASE WHEN $3 THEN 1 ELSE 0 END, CASE WHEN $3 THEN 0 ELSE 1 END, $4)
+		ON CONFLICT (summoner_id, season, queue_type) DO UPDATE
 		SET total_games = placement_games.total_games + 1,
 			wins = placement_games.wins + CASE WHEN $3 THEN 1 ELSE 0 END,
 			losses = placement_games.losses + CASE WHEN $3 THEN 0 ELSE 1 END,
 			updated_at = CURRENT_TIMESTAMP
 		WHERE placement_games.summoner_id = $1 AND placement_games.season = $2
-	`, summonerUUID, seasonStr, isWin)
+			AND placement_games.queue_type = $4
+	`, summonerUUID, seasonStr, isWin, queueType)
 	if err != nil {
 		return fmt.Errorf("error incrementing placement games: %w", err)
 	}
@@ -293,16 +360,16 @@ func (s *Storage) IncrementPlacementGames(summonerUUID uuid.UUID, isWin bool) er
 	return nil
 }
 
-// GetCurrentPlacementGames retrieves the current placement games status for a summoner
-func (s *Storage) GetCurrentPlacementGames(summonerUUID uuid.UUID) (*riotapi.PlacementStatus, error) {
+// GetCurrentPlacementGames retrieves the current placement games status of a ranked queue type for a summoner
+func (s *Storage) GetCurrentPlacementGames(ctx context.Context, summonerUUID uuid.UUID, queueType string) (*riotapi.PlacementStatus, error) {
 	currentSeason := s.GetCurrentSeason()
 
 	var status riotapi.PlacementStatus
-	err := s.db.QueryRow(`
+	err := s.db.QueryRowContext(ctx, `
 		SELECT total_games, wins, losses
 		FROM placement_games
-		WHERE summoner_id = $1 AND season = $2
-	`, summonerUUID, SeasonToString(currentSeason)).Scan(&status.TotalGames, &status.Wins, &status.Losses)
+		WHERE summoner_id = $1 AND season = $2 AND queue_type = $3
+	`, summonerUUID, currentSeason.String(), queueType).Scan(&status.TotalGames, &status.Wins, &status.Losses)
 	if err == sql.ErrNoRows {
 		// If no row exists, return an initialized PlacementStatus
 		return &riotapi.PlacementStatus{
@@ -318,48 +385,26 @@ func (s *Storage) GetCurrentPlacementGames(summonerUUID uuid.UUID) (*riotapi.Pla
 	return &status, nil
 }
 
-// GetCurrentSeason returns the current season based on the current date
-func (s *Storage) GetCurrentSeason() Season {
-	now := time.Now()
-	year := now.Year()
-	month := now.Month()
-	day := now.Day()
-
-	// split 1 starts the 10th of january
-	// split 2 starts the 25th of may
-	// split 3 start the 25th of september
-
-	if month == time.January && day < 10 {
-		return Season{Year: year - 1, Split: 3}
-	} else if month < time.May || (month == time.May && day <= 24) {
-		return Season{Year: year, Split: 1}
-	} else if month < time.September || (month == time.September && day < 25) {
-		return Season{Year: year, Split: 2}
-	} else {
-		return Season{Year: year, Split: 3}
-	}
-}
-
-// SeasonToString converts a Season struct to a string representation
-func SeasonToString(s Season) string {
-	return fmt.Sprintf("S%d S%d", s.Year, s.Split)
+// GetCurrentSeason returns the split in progress according to the season calendar.
+func (s *Storage) GetCurrentSeason() season.Split {
+	return s.calendar.Current()
 }
 
 // AddPlacementMatch adds a new match record to the database for a given summoner that is in placement games.
 // It also updates the placement_games table to reflect the new match.
-func (s *Storage) AddPlacementMatch(summonerUUID uuid.UUID, matchData *riotapi.MatchData) error {
-	tx, err := s.db.Begin()
+func (s *Storage) AddPlacementMatch(ctx context.Context, summonerUUID uuid.UUID, matchData *riotapi.MatchData) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	err = s.insertMatchData(summonerUUID, matchData)
+	err = insertMatchData(ctx, tx, summonerUUID, matchData)
 	if err != nil {
 		return fmt.Errorf("error inserting match data: %w", err)
 	}
 
-	err = s.CreateNewRowInLPHistory(summonerUUID, matchData.MatchID, 0, 0, "UNRANKED", "")
+	err = insertLPHistory(ctx, tx, summonerUUID, riotapi.LeagueQueueType(matchData.QueueID), matchData.MatchID, 0, 0, "UNRANKED", "")
 	if err != nil {
 		return fmt.Errorf("error creating new row in lp history: %w", err)
 	}
@@ -372,9 +417,30 @@ func (s *Storage) AddPlacementMatch(summonerUUID uuid.UUID, matchData *riotapi.M
 	return nil
 }
 
-// insertMatchData inserts match data for a summoner into the database.
-func (s *Storage) insertMatchData(summonerUUID uuid.UUID, matchData *riotapi.MatchData) error {
-	_, err := s.db.Exec(string(insertMatchDataSQL), summonerUUID, matchData.MatchID, matchData.ChampionName, matchData.GameCreation,
+// AddMatch adds a new match record to the database for a given summoner without touching LP history,
+// used for unranked matches and matches whose LP change cannot be known.
+func (s *Storage) AddMatch(ctx context.Context, summonerUUID uuid.UUID, matchData *riotapi.MatchData) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertMatchData(ctx, tx, summonerUUID, matchData); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertMatchData inserts match data for a summoner, along with its players and laning stats, within tx
+// so a match is never partly stored.
+func insertMatchData(ctx context.Context, tx *sql.Tx, summonerUUID uuid.UUID, matchData *riotapi.MatchData) error {
+	_, err := tx.ExecContext(ctx, string(insertMatchDataSQL), summonerUUID, matchData.MatchID, matchData.ChampionName, matchData.GameCreation,
 		matchData.GameDuration, matchData.GameEndTimestamp, matchData.GameID, matchData.QueueID,
 		matchData.GameMode, matchData.GameType, matchData.Kills, matchData.Deaths, matchData.Assists,
 		matchData.Result, matchData.Pentakills, matchData.TeamPosition, matchData.TeamDamagePercentage, matchData.KillParticipation,
@@ -385,14 +451,105 @@ func (s *Storage) insertMatchData(summonerUUID uuid.UUID, matchData *riotapi.Mat
 		return fmt.Errorf("error inserting match data: %w", err)
 	}
 
+	if err := insertMatchParticipants(ctx, tx, matchData); err != nil {
+		return err
+	}
+
+	return insertMatchLaningStats(ctx, tx, summonerUUID, matchData)
+}
+
+// insertMatchLaningStats inserts the timeline stats of a match for a summoner, when they were computed.
+func insertMatchLaningStats(ctx context.Context, tx *sql.Tx, summonerUUID uuid.UUID, matchData *riotapi.MatchData) error {
+	laning := matchData.Laning
+	if laning == nil {
+		return nil
+	}
+
+	var opponentChampion sql.NullString
+	if laning.OpponentChampion != "" {
+		opponentChampion = sql.NullString{String: laning.OpponentChampion, Valid: true}
+	}
+
+	at10 := laningDiffColumns(laning.At10)
+	at15 := laningDiffColumns(laning.At15)
+
+	_, err := tx.ExecContext(ctx, string(insertMatchLaningStatsSQL), summonerUUID, matchData.MatchID, opponentChampion,
+		at10[0], at10[1], at10[2], at15[0], at15[1], at15[2],
+		laning.FirstBloodKill, laning.FirstBloodAssist, laning.FirstBloodVictim,
+		laning.TeamObjectives, laning.ObjectivesParticipated)
+	if err != nil {
+		return fmt.Errorf("error inserting match laning stats: %w", err)
+	}
+
+	return nil
+}
+
+// laningDiffColumns returns the gold, CS and XP columns of a laning difference, NULL when it is unknown.
+func laningDiffColumns(diff *riotapi.LaningDiff) [3]sql.NullInt64 {
+	if diff == nil {
+		return [3]sql.NullInt64{}
+	}
+
+	return [3]sql.NullInt64{
+		{Int64: int64(diff.Gold), Valid: true},
+		{Int64: int64(diff.CS), Valid: true},
+		{Int64: int64(diff.XP), Valid: true},
+	}
+}
+
+// insertMatchParticipants inserts every player of a match into the database.
+// Players already stored for this match (by another tracked summoner of the same game) are skipped.
+func insertMatchParticipants(ctx context.Context, tx *sql.Tx, matchData *riotapi.MatchData) error {
+	for _, p := range matchData.Participants {
+		_, err := tx.ExecContext(ctx, string(insertMatchParticipantSQL), matchData.MatchID, p.PUUID, p.RiotIDGameName, p.RiotIDTagLine,
+			p.ChampionID, p.ChampionName, p.TeamID, p.TeamPosition, p.Kills, p.Deaths, p.Assists,
+			p.TotalDamageDealtToChampions, p.GoldEarned, p.TotalMinionsKilled+p.NeutralMinionsKilled,
+			pq.Array(p.Items), p.Win)
+		if err != nil {
+			return fmt.Errorf("error inserting match participant: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// GetMatchParticipants retrieves every player of a stored match, ordered by team.
+// Minions and monsters killed are returned together in TotalMinionsKilled.
+func (s *Storage) GetMatchParticipants(ctx context.Context, matchID string) ([]riotapi.MatchParticipant, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectMatchParticipantsSQL), matchID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying match participants: %w", err)
+	}
+	defer rows.Close()
+
+	var participants []riotapi.MatchParticipant
+	for rows.Next() {
+		var p riotapi.MatchParticipant
+		var items []int64
+		if err := rows.Scan(
+			&p.PUUID, &p.RiotIDGameName, &p.RiotIDTagLine, &p.ChampionID, &p.ChampionName,
+			&p.TeamID, &p.TeamPosition, &p.Kills, &p.Deaths, &p.Assists, &p.TotalDamageDealtToChampions,
+			&p.GoldEarned, &p.TotalMinionsKilled, pq.Array(&items), &p.Win,
+		); err != nil {
+			return nil, err
+		}
+		p.Items = int64sToInts(items)
+		participants = append(participants, p)
+	}
+
+	return participants, rows.Err()
+}
+
 // CreateNewRowInLPHistory inserts a new record into the lp_history table for a summoner.
 // It captures the LP change, new LP total, tier, and rank for a specific match,
 // enabling detailed tracking of a summoner's rank progression over time.
-func (s *Storage) CreateNewRowInLPHistory(summonerUUID uuid.UUID, matchID string, lpChange, newLP int, tier, rank string) error {
-	_, err := s.db.Exec(string(insertLDataInLPHistorySQL), summonerUUID, matchID, lpChange, newLP, tier, rank)
+func (s *Storage) CreateNewRowInLPHistory(ctx context.Context, summonerUUID uuid.UUID, queueType, matchID string, lpChange, newLP int, tier, rank string) error {
+	return insertLPHistory(ctx, s.db, summonerUUID, queueType, matchID, lpChange, newLP, tier, rank)
+}
+
+// insertLPHistory inserts a record into the lp_history table, see CreateNewRowInLPHistory.
+func insertLPHistory(ctx context.Context, db execer, summonerUUID uuid.UUID, queueType, matchID string, lpChange, newLP int, tier, rank string) error {
+	_, err := db.ExecContext(ctx, string(insertLDataInLPHistorySQL), summonerUUID, matchID, lpChange, newLP, tier, rank, queueType)
 	if err != nil {
 		return fmt.Errorf("error inserting LP history: %w", err)
 	}
@@ -400,9 +557,27 @@ func (s *Storage) CreateNewRowInLPHistory(summonerUUID uuid.UUID, matchID string
 	return nil
 }
 
-// updateLeagueEntry updates lp, tier and rank in league_entries for a summoner in the database.
-func (s *Storage) UpdateLeagueEntry(summonerUUID uuid.UUID, newLP int, newTier, newRank string) error {
-	_, err := s.db.Exec(string(updateLeagueEntriesSQL), newLP, newTier, newRank, summonerUUID)
+// AddRankEvent records a rank change that happened without any match.
+func (s *Storage) AddRankEvent(ctx context.Context, summonerUUID uuid.UUID, event RankEvent) error {
+	_, err := s.db.ExecContext(ctx, string(insertRankEventSQL), summonerUUID, event.QueueType, string(event.Type), event.LPChange,
+		event.Previous.PrevTier, event.Previous.PrevRank, event.Previous.PrevLP, event.NewTier, event.NewRank, event.NewLP)
+	if err != nil {
+		return fmt.Errorf("error inserting rank event: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateLeagueEntry updates lp, tier, rank, wins and losses of the queue type of entry in league_entries
+// for a summoner in the database.
+func (s *Storage) UpdateLeagueEntry(ctx context.Context, summonerUUID uuid.UUID, entry *riotapi.LeagueEntry) error {
+	return updateLeagueEntry(ctx, s.db, summonerUUID, entry)
+}
+
+// updateLeagueEntry updates a row of league_entries, see UpdateLeagueEntry.
+func updateLeagueEntry(ctx context.Context, db execer, summonerUUID uuid.UUID, entry *riotapi.LeagueEntry) error {
+	_, err := db.ExecContext(ctx, string(updateLeagueEntriesSQL), summonerUUID, entry.QueueType, entry.LeaguePoints, entry.Tier, entry.Rank,
+		entry.Wins, entry.Losses)
 	if err != nil {
 		return fmt.Errorf("error updating league entry: %w", err)
 	}
@@ -415,8 +590,8 @@ func (s *Storage) CalculateLPChange(oldTier, newTier, oldRank, newRank string, o
 	oldTier = strings.ToUpper(oldTier)
 	newTier = strings.ToUpper(newTier)
 
-	highTiers := map[string]bool{"MASTER": true, "GRANDMASTER": true, "CHALLENGER": true}
-	if highTiers[oldTier] && highTiers[newTier] {
+	// apex tiers share a single LP ladder, Master 0LP being the bottom of it
+	if utils.IsApexTier(oldTier) && utils.IsApexTier(newTier) {
 		return newLP - oldLP
 	}
 
@@ -425,7 +600,7 @@ func (s *Storage) CalculateLPChange(oldTier, newTier, oldRank, newRank string, o
 
 	var lpChange int
 	if oldTier != newTier {
-		if tierOrder[newTier] > tierOrder[oldTier] {
+		if utils.TierValue(newTier) > utils.TierValue(oldTier) {
 			// Promotion to a new tier
 			lpChange = (100 - oldLP) + newLP
 		} else {
@@ -448,17 +623,60 @@ func (s *Storage) CalculateLPChange(oldTier, newTier, oldRank, newRank string, o
 	return lpChange
 }
 
-func (s *Storage) CheckAndUpdateSummonerInfo(summonerUUID uuid.UUID, fullName string, newProfileIconID int) error {
-	_, err := s.db.Exec(`
+// CheckAndUpdateSummonerInfo updates the Riot ID and profile icon of a summoner when they changed,
+// and records that their Riot ID was checked.
+// A replaced Riot ID is kept in summoner_name_history and returned, "" when it didn't change.
+func (s *Storage) CheckAndUpdateSummonerInfo(ctx context.Context, summonerUUID uuid.UUID, fullName string, newProfileIconID int) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentName string
+	if err := tx.QueryRowContext(ctx, string(selectSummonerNameForUpdateSQL), summonerUUID).Scan(&currentName); err != nil {
+		return "", fmt.Errorf("error querying summoner name: %w", err)
+	}
+
+	var previousName string
+	if currentName != fullName {
+		previousName = currentName
+		if _, err := tx.ExecContext(ctx, string(insertSummonerNameHistorySQL), summonerUUID, previousName); err != nil {
+			return "", fmt.Errorf("error inserting summoner name history: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
         UPDATE summoners
-        SET name = $2, profile_icon_id = $3, updated_at = CURRENT_TIMESTAMP
-        WHERE id = $1 AND (name != $2 OR profile_icon_id != $3)
+        SET name = $2, profile_icon_id = $3, name_checked_at = CURRENT_TIMESTAMP,
+            updated_at = CASE WHEN name != $2 OR profile_icon_id != $3 THEN CURRENT_TIMESTAMP ELSE updated_at END
+        WHERE id = $1
     `, summonerUUID, fullName, newProfileIconID)
 	if err != nil {
-		return fmt.Errorf("error updating summoner info: %w", err)
+		return "", fmt.Errorf("error updating summoner info: %w", err)
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return previousName, nil
+}
+
+// ResolveSummonerName returns the current Riot ID of the summoner tracked in a guild that is or used to be
+// called name, case-insensitively. It returns ErrSummonerNotFound when no tracked summoner ever had that name.
+func (s *Storage) ResolveSummonerName(ctx context.Context, guildID, name string) (string, error) {
+	var currentName string
+
+	err := s.db.QueryRowContext(ctx, string(selectSummonerNameByAnyNameSQL), guildID, name).Scan(&currentName)
+	if err == sql.ErrNoRows {
+		return "", ErrSummonerNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error resolving summoner name: %w", err)
+	}
+
+	return currentName, nil
 }
 
 // ListSummoners retrieves and returns a list of summoners with their ranks for a given guild id.
@@ -475,8 +693,8 @@ func (s *Storage) ListSummoners(guildID string) ([]riotapi.Summoner, error) {
 		var s riotapi.Summoner
 		if err := rows.Scan(
 			&s.RiotSummonerID, &s.RiotAccountID, &s.SummonerPUUID,
-			&s.ProfileIconID, &s.RevisionDate, &s.SummonerLevel, &s.Name,
-			&s.Rank, &s.LeaguePoints,
+			&s.ProfileIconID, &s.RevisionDate, &s.SummonerLevel, &s.Region, &s.Name,
+			&s.Rank, &s.LeaguePoints, &s.FlexRank, &s.FlexLeaguePoints,
 		); err != nil {
 			return nil, err
 		}
@@ -495,16 +713,18 @@ func (s *Storage) GetGuildChannelID(guildID string) (string, error) {
 	return channelID, err
 }
 
-// GetLastMatchID retrieves the most recent match ID for a given summoner PUUID.
-func (s *Storage) GetLastMatchID(puuid string) (string, error) {
+// GetLastMatch retrieves the most recent match ID of a queue and its creation timestamp (epoch ms)
+// for a given summoner PUUID.
+func (s *Storage) GetLastMatch(ctx context.Context, puuid string, queueID int) (string, int64, error) {
 	var matchID string
+	var gameCreation int64
 
-	err := s.db.QueryRow(string(selectLastMatchIDSQL), puuid).Scan(&matchID)
+	err := s.db.QueryRowContext(ctx, string(selectLastMatchSQL), puuid, queueID).Scan(&matchID, &gameCreation)
 	if err == sql.ErrNoRows {
-		return "", nil
+		return "", 0, nil
 	}
 
-	return matchID, err
+	return matchID, gameCreation, err
 }
 
 // RemoveChannelFromGuild removes the association between a channel and a guild in the database.
@@ -527,10 +747,10 @@ func (s *Storage) RemoveChannelFromGuild(guildID, channelID string) error {
 }
 
 // GetSummonerUUIDFromRiotID retrieves the UUID of a summoner from riot_summoner_id.
-func (s *Storage) GetSummonerUUIDFromRiotID(riotSummonerID string) (uuid.UUID, error) {
+func (s *Storage) GetSummonerUUIDFromRiotID(ctx context.Context, riotSummonerID string) (uuid.UUID, error) {
 	var summonerUUID uuid.UUID
 
-	err := s.db.QueryRow("SELECT id FROM summoners WHERE riot_summoner_id = $1", riotSummonerID).Scan(&summonerUUID)
+	err := s.db.QueryRowContext(ctx, "SELECT id FROM summoners WHERE riot_summoner_id = $1", riotSummonerID).Scan(&summonerUUID)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("error fetching summoner UUID: %w", err)
 	}
@@ -538,12 +758,12 @@ func (s *Storage) GetSummonerUUIDFromRiotID(riotSummonerID string) (uuid.UUID, e
 	return summonerUUID, nil
 }
 
-// GetRankBySummonerUUID retrieves the rank from leagueEntries using the summoner UUID.
+// GetPreviousRank retrieves the rank of a queue type from leagueEntries using the summoner UUID.
 // This should be called before updating entries.
-func (s *Storage) GetPreviousRank(summonerUUID uuid.UUID) (*PreviousRank, error) {
+func (s *Storage) GetPreviousRank(ctx context.Context, summonerUUID uuid.UUID, queueType string) (*PreviousRank, error) {
 	var prevRank PreviousRank
 
-	err := s.db.QueryRow(string(selectRankInLeagueEntriesSQL), summonerUUID).Scan(&prevRank.PrevTier, &prevRank.PrevRank, &prevRank.PrevLP)
+	err := s.db.QueryRowContext(ctx, string(selectRankInLeagueEntriesSQL), summonerUUID, queueType).Scan(&prevRank.PrevTier, &prevRank.PrevRank, &prevRank.PrevLP, &prevRank.PrevGames)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -554,42 +774,547 @@ func (s *Storage) GetPreviousRank(summonerUUID uuid.UUID) (*PreviousRank, error)
 	return &prevRank, nil
 }
 
-func (s *Storage) GetAllSummonersWithGuilds() ([]SummonerWithGuilds, error) {
-	rows, err := s.db.Query(string(selectSummonerInGuildSQL))
+// GetAllSummonersWithGuilds retrieves every tracked summoner along with the guilds tracking them
+// and the queues each guild tracks for them.
+func (s *Storage) GetAllSummonersWithGuilds(ctx context.Context) ([]SummonerWithGuilds, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectSummonerInGuildSQL))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanSummonersWithGuilds(rows)
+}
+
+// GetDueSummonersWithGuilds retrieves the tracked summoners whose next check is due at now, or who were never
+// checked, like GetAllSummonersWithGuilds.
+func (s *Storage) GetDueSummonersWithGuilds(ctx context.Context, now time.Time) ([]SummonerWithGuilds, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectDueSummonerInGuildSQL), now)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanSummonersWithGuilds(rows)
+}
+
+// scanSummonersWithGuilds groups rows of selectSummonerInGuildSQL, one per summoner and guild, by summoner.
+func scanSummonersWithGuilds(rows *sql.Rows) ([]SummonerWithGuilds, error) {
 	var summoners []SummonerWithGuilds
+	var lastSummonerUUID uuid.UUID
 	for rows.Next() {
-		var s SummonerWithGuilds
-		var guildIDs string
+		var summonerUUID uuid.UUID
+		var summoner riotapi.Summoner
+		var lastActiveAt, nameCheckedAt sql.NullTime
+		var guildID string
+		var trackedQueues []int64
 		err := rows.Scan(
-			&s.Summoner.RiotSummonerID, &s.Summoner.RiotAccountID, &s.Summoner.SummonerPUUID,
-			&s.Summoner.ProfileIconID, &s.Summoner.RevisionDate, &s.Summoner.SummonerLevel, &s.Summoner.Name,
-			&guildIDs,
+			&summonerUUID,
+			&summoner.RiotSummonerID, &summoner.RiotAccountID, &summoner.SummonerPUUID,
+			&summoner.ProfileIconID, &summoner.RevisionDate, &summoner.SummonerLevel, &summoner.Region, &summoner.Name,
+			&lastActiveAt, &nameCheckedAt, &guildID, pq.Array(&trackedQueues),
 		)
 		if err != nil {
 			return nil, err
 		}
-		s.GuildIDs = strings.Split(strings.Trim(guildIDs, "{}"), ",")
-		summoners = append(summoners, s)
+
+		// rows are ordered by summoner, a new summoner starts when the id changes
+		if len(summoners) == 0 || summonerUUID != lastSummonerUUID {
+			summoners = append(summoners, SummonerWithGuilds{
+				Summoner:      summoner,
+				GuildQueues:   make(map[string][]int),
+				LastActiveAt:  lastActiveAt.Time,
+				NameCheckedAt: nameCheckedAt.Time,
+			})
+			lastSummonerUUID = summonerUUID
+		}
+
+		current := &summoners[len(summoners)-1]
+		current.GuildIDs = append(current.GuildIDs, guildID)
+		current.GuildQueues[guildID] = int64sToInts(trackedQueues)
 	}
 
 	return summoners, rows.Err()
 }
 
+// UpdateSummonerSchedule stores the revision date seen during a check of a summoner, when they were last active
+// and when to check them next.
+func (s *Storage) UpdateSummonerSchedule(ctx context.Context, summonerUUID uuid.UUID, revisionDate int64, lastActiveAt, nextCheckAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, string(updateSummonerScheduleSQL), summonerUUID, revisionDate, lastActiveAt, nextCheckAt); err != nil {
+		return fmt.Errorf("error updating summoner schedule: %w", err)
+	}
+
+	return nil
+}
+
+// MarkSummonersActive records that the summoners of the given PUUIDs are playing right now, or just did, making them
+// due for a check. PUUIDs of summoners who aren't tracked are ignored.
+func (s *Storage) MarkSummonersActive(ctx context.Context, puuids []string) error {
+	if _, err := s.db.ExecContext(ctx, string(updateSummonersActiveSQL), pq.Array(puuids)); err != nil {
+		return fmt.Errorf("error marking summoners active: %w", err)
+	}
+
+	return nil
+}
+
+// GetGuildTrackedQueues retrieves the queue ids tracked in a guild.
+func (s *Storage) GetGuildTrackedQueues(guildID string) ([]int, error) {
+	var trackedQueues []int64
+
+	err := s.db.QueryRow(string(selectGuildTrackedQueuesSQL), guildID).Scan(pq.Array(&trackedQueues))
+	if err == sql.ErrNoRows {
+		return riotapi.DefaultTrackedQueues, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying tracked queues: %w", err)
+	}
+
+	return int64sToInts(trackedQueues), nil
+}
+
+// SetGuildTrackedQueues sets the queue ids tracked in a guild.
+func (s *Storage) SetGuildTrackedQueues(guildID string, queueIDs []int) error {
+	_, err := s.db.Exec(string(updateGuildTrackedQueuesSQL), guildID, pq.Array(queueIDs))
+	if err != nil {
+		return fmt.Errorf("error updating tracked queues: %w", err)
+	}
+
+	return nil
+}
+
+// SetSummonerTrackedQueues sets the queue ids tracked for a summoner in a guild.
+// A nil queueIDs makes the summoner follow the queues of the guild again.
+func (s *Storage) SetSummonerTrackedQueues(guildID, summonerName string, queueIDs []int) error {
+	var trackedQueues interface{}
+	if queueIDs != nil {
+		trackedQueues = pq.Array(queueIDs)
+	}
+
+	result, err := s.db.Exec(string(updateSummonerTrackedQueuesSQL), guildID, summonerName, trackedQueues)
+	if err != nil {
+		return fmt.Errorf("error updating summoner tracked queues: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrSummonerNotFound
+	}
+
+	return nil
+}
+
+// int64sToInts converts the int64 values scanned from a postgres INTEGER[] to ints.
+func int64sToInts(values []int64) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+
+	return ints
+}
+
+// AddLiveGameMessage remembers the message announcing a live game (by its future match ID) in a guild.
+func (s *Storage) AddLiveGameMessage(ctx context.Context, guildID, matchID, channelID, messageID string) error {
+	_, err := s.db.ExecContext(ctx, string(insertLiveGameMessageSQL), guildID, matchID, channelID, messageID)
+	if err != nil {
+		return fmt.Errorf("error inserting live game message: %w", err)
+	}
+
+	return nil
+}
+
+// GetLiveGameMessage retrieves the message announcing a live game in a guild, nil if the game wasn't announced.
+func (s *Storage) GetLiveGameMessage(ctx context.Context, guildID, matchID string) (*LiveGameMessage, error) {
+	message := LiveGameMessage{GuildID: guildID, MatchID: matchID}
+
+	err := s.db.QueryRowContext(ctx, string(selectLiveGameMessageSQL), guildID, matchID).Scan(&message.ChannelID, &message.MessageID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying live game message: %w", err)
+	}
+
+	return &message, nil
+}
+
+// DeleteStaleLiveGameMessages forgets live game messages created before the given time.
+func (s *Storage) DeleteStaleLiveGameMessages(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, string(deleteStaleLiveGameMessagesSQL), before)
+	if err != nil {
+		return fmt.Errorf("error deleting stale live game messages: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimMatchAnnouncement records that the result of a summoner in a match is being announced in a guild.
+// It reports false when it already was, by this instance or another one.
+func (s *Storage) ClaimMatchAnnouncement(ctx context.Context, guildID, matchID, summonerPUUID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, string(insertMatchAnnouncementSQL), guildID, matchID, summonerPUUID)
+	if err != nil {
+		return false, fmt.Errorf("error claiming match announcement: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// DeleteStaleMatchAnnouncements forgets match announcements created before the given time.
+func (s *Storage) DeleteStaleMatchAnnouncements(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, string(deleteStaleMatchAnnouncementsSQL), before)
+	if err != nil {
+		return fmt.Errorf("error deleting stale match announcements: %w", err)
+	}
+
+	return nil
+}
+
+// GetGuildsWithChannel retrieves every guild with a channel set for updates.
+func (s *Storage) GetGuildsWithChannel(ctx context.Context) ([]Guild, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectGuildsWithChannelSQL))
+	if err != nil {
+		return nil, fmt.Errorf("error querying guilds: %w", err)
+	}
+	defer rows.Close()
+
+	return scanGuilds(rows)
+}
+
+// GetGuildsWithChannelInRegion retrieves the guilds with a channel set for updates that track a summoner of region.
+func (s *Storage) GetGuildsWithChannelInRegion(ctx context.Context, region string) ([]Guild, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectGuildsWithChannelInRegionSQL), region)
+	if err != nil {
+		return nil, fmt.Errorf("error querying guilds: %w", err)
+	}
+	defer rows.Close()
+
+	return scanGuilds(rows)
+}
+
+// scanGuilds reads rows of guild id, name and channel id.
+func scanGuilds(rows *sql.Rows) ([]Guild, error) {
+	var guilds []Guild
+	for rows.Next() {
+		var guild Guild
+		var name sql.NullString
+		if err := rows.Scan(&guild.ID, &name, &guild.ChannelID); err != nil {
+			return nil, err
+		}
+		guild.Name = name.String
+		guilds = append(guilds, guild)
+	}
+
+	return guilds, rows.Err()
+}
+
+// GetTrackedRegions retrieves the platforms of the tracked summoners.
+func (s *Storage) GetTrackedRegions(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectTrackedRegionsSQL))
+	if err != nil {
+		return nil, fmt.Errorf("error querying tracked regions: %w", err)
+	}
+	defer rows.Close()
+
+	var regions []string
+	for rows.Next() {
+		var region string
+		if err := rows.Scan(&region); err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+
+	return regions, rows.Err()
+}
+
+// HasStatusAnnouncement reports whether a Riot status incident or maintenance (see riotapi.StatusIncident.Type)
+// was already posted in a guild.
+func (s *Storage) HasStatusAnnouncement(ctx context.Context, guildID, statusType string, incidentID int64) (bool, error) {
+	var exists bool
+
+	err := s.db.QueryRowContext(ctx, string(selectStatusAnnouncementSQL), guildID, statusType, incidentID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error querying status announcement: %w", err)
+	}
+
+	return exists, nil
+}
+
+// AddStatusAnnouncement remembers that a Riot status incident or maintenance was posted in a guild.
+func (s *Storage) AddStatusAnnouncement(ctx context.Context, guildID, statusType string, incidentID int64) error {
+	_, err := s.db.ExecContext(ctx, string(insertStatusAnnouncementSQL), guildID, statusType, incidentID)
+	if err != nil {
+		return fmt.Errorf("error inserting status announcement: %w", err)
+	}
+
+	return nil
+}
+
+// AcquireLease takes or renews the lease name for holder during ttl and reports whether holder has it.
+// The lease can't be taken while another holder has it and it didn't expire. The database clock is used,
+// so the clocks of the instances don't need to agree.
+func (s *Storage) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	var current string
+
+	err := s.db.QueryRowContext(ctx, string(acquireLeaseSQL), name, holder, ttl.Seconds()).Scan(&current)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error acquiring lease: %w", err)
+	}
+
+	return current == holder, nil
+}
+
+// ReleaseLease gives up the lease name if holder has it, so another instance can take it right away.
+func (s *Storage) ReleaseLease(ctx context.Context, name, holder string) error {
+	if _, err := s.db.ExecContext(ctx, string(releaseLeaseSQL), name, holder); err != nil {
+		return fmt.Errorf("error releasing lease: %w", err)
+	}
+
+	return nil
+}
+
+// SaveRiotAPIKey stores the Riot API key set with /apikey, so that every instance switches to it.
+func (s *Storage) SaveRiotAPIKey(ctx context.Context, apiKey string) error {
+	if _, err := s.db.ExecContext(ctx, string(upsertRiotAPIKeySQL), apiKey); err != nil {
+		return fmt.Errorf("error saving Riot API key: %w", err)
+	}
+
+	return nil
+}
+
+// GetRiotAPIKey returns the Riot API key set with /apikey and when it was set, an empty key if none was.
+func (s *Storage) GetRiotAPIKey(ctx context.Context) (string, time.Time, error) {
+	var apiKey string
+	var updatedAt time.Time
+
+	err := s.db.QueryRowContext(ctx, string(selectRiotAPIKeySQL)).Scan(&apiKey, &updatedAt)
+	if err == sql.ErrNoRows {
+		return "", time.Time{}, nil
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error querying Riot API key: %w", err)
+	}
+
+	return apiKey, updatedAt, nil
+}
+
+// GetRecentResults retrieves whether a summoner won each of their last limit matches of a queue, newest first.
+// Matches shorter than minDuration seconds (remakes) are left out.
+func (s *Storage) GetRecentResults(ctx context.Context, summonerUUID uuid.UUID, queueID, minDuration, limit int) ([]bool, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectRecentResultsSQL), summonerUUID, queueID, minDuration, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying recent results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []bool
+	for rows.Next() {
+		var win bool
+		if err := rows.Scan(&win); err != nil {
+			return nil, fmt.Errorf("error scanning recent result: %w", err)
+		}
+		results = append(results, win)
+	}
+
+	return results, rows.Err()
+}
+
+// RecordSeasonPeak stores a rank reached by a summoner in a queue as their peak of the current season when it is
+// higher than the stored one (higher LP in the same division included). It returns the previous peak, nil when
+// there was none this season.
+func (s *Storage) RecordSeasonPeak(ctx context.Context, summonerUUID uuid.UUID, queueType, tier, rank string, leaguePoints int) (*PreviousRank, error) {
+	currentSeason := s.GetCurrentSeason().String()
+
+	var peak PreviousRank
+	err := s.db.QueryRowContext(ctx, string(upsertSeasonPeakSQL), summonerUUID, queueType, currentSeason, tier, rank, leaguePoints).Scan(&peak.PrevTier, &peak.PrevRank, &peak.PrevLP)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating season peak: %w", err)
+	}
+
+	return &peak, nil
+}
+
+// GetLastSeasonRollover returns the key of the last split the bot saw start, "" before the first rollover check.
+func (s *Storage) GetLastSeasonRollover(ctx context.Context) (string, error) {
+	var last string
+
+	err := s.db.QueryRowContext(ctx, string(selectLastSeasonRolloverSQL)).Scan(&last)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error querying last season rollover: %w", err)
+	}
+
+	return last, nil
+}
+
+// RollOverSeason records that the split started began after the split ended. The ranks of every tracked summoner
+// are snapshotted into season_results as their final ranks of ended, then reset to unranked with their placements
+// of started begun, so their next games are tracked as placements. With no ended split, started is only recorded.
+// It reports false when another instance already rolled over to started.
+func (s *Storage) RollOverSeason(ctx context.Context, ended, started string) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, string(insertSeasonRolloverSQL), started)
+	if err != nil {
+		return false, fmt.Errorf("error inserting season rollover: %w", err)
+	}
+	if inserted, err := res.RowsAffected(); err != nil || inserted == 0 {
+		return false, err
+	}
+
+	if ended != "" {
+		if _, err := tx.ExecContext(ctx, string(insertSeasonResultsSQL), ended); err != nil {
+			return false, fmt.Errorf("error inserting season results: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, string(insertSeasonPlacementsSQL), ended, started); err != nil {
+			return false, fmt.Errorf("error initializing placements: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, string(resetSeasonLeagueEntriesSQL), ended); err != nil {
+			return false, fmt.Errorf("error resetting league entries: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetSeasonResults retrieves the final ranks of a split of the summoners tracked in a guild.
+func (s *Storage) GetSeasonResults(ctx context.Context, guildID, seasonKey string) ([]SeasonResult, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectGuildSeasonResultsSQL), guildID, seasonKey)
+	if err != nil {
+		return nil, fmt.Errorf("error querying season results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SeasonResult
+	for rows.Next() {
+		var result SeasonResult
+		if err := rows.Scan(&result.SummonerName, &result.QueueType, &result.Tier, &result.Rank, &result.LeaguePoints,
+			&result.PeakTier, &result.PeakRank, &result.PeakLeaguePoints); err != nil {
+			return nil, fmt.Errorf("error scanning season result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// GetChampionMasteries retrieves the last known mastery of a summoner on every champion, indexed by champion id.
+func (s *Storage) GetChampionMasteries(ctx context.Context, summonerUUID uuid.UUID) (map[int]riotapi.ChampionMastery, error) {
+	rows, err := s.db.QueryContext(ctx, string(selectChampionMasteriesSQL), summonerUUID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying champion masteries: %w", err)
+	}
+	defer rows.Close()
+
+	masteries := make(map[int]riotapi.ChampionMastery)
+	for rows.Next() {
+		var mastery riotapi.ChampionMastery
+		var lastPlayTime sql.NullInt64
+		if err := rows.Scan(&mastery.ChampionID, &mastery.ChampionLevel, &mastery.ChampionPoints, &lastPlayTime); err != nil {
+			return nil, err
+		}
+		mastery.LastPlayTime = lastPlayTime.Int64
+		masteries[mastery.ChampionID] = mastery
+	}
+
+	return masteries, rows.Err()
+}
+
+// UpdateChampionMasteries stores the current mastery of a summoner on the given champions.
+func (s *Storage) UpdateChampionMasteries(ctx context.Context, summonerUUID uuid.UUID, masteries []riotapi.ChampionMastery) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, mastery := range masteries {
+		_, err := tx.ExecContext(ctx, string(upsertChampionMasterySQL), summonerUUID, mastery.ChampionID, mastery.ChampionLevel, mastery.ChampionPoints, mastery.LastPlayTime)
+		if err != nil {
+			return fmt.Errorf("error updating champion mastery: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 type Guild struct {
 	ID        string
 	Name      string
 	ChannelID string
 }
 
+type LiveGameMessage struct {
+	GuildID   string
+	MatchID   string
+	ChannelID string
+	MessageID string
+}
+
+// RankEventType is what caused a rank change that happened without any match.
+type RankEventType string
+
+const (
+	// RankEventDodge is a champion select dodge, -5 then -15 LP below Master
+	RankEventDodge RankEventType = "dodge"
+	// RankEventDecay is LP lost from inactivity in Master and above
+	RankEventDecay RankEventType = "decay"
+	// RankEventLadder is a move between Master, Grandmaster and Challenger at the daily ladder update
+	RankEventLadder RankEventType = "ladder"
+	// RankEventSeasonReset is the rank being removed at the start of a season or split
+	RankEventSeasonReset RankEventType = "season_reset"
+	// RankEventCorrection is any other change, e.g. LP restored or removed by Riot
+	RankEventCorrection RankEventType = "correction"
+)
+
+// RankEvent is a rank change of a queue that happened without any match.
+type RankEvent struct {
+	Type      RankEventType
+	QueueType string
+	LPChange  int
+	Previous  PreviousRank
+	NewTier   string
+	NewRank   string
+	NewLP     int
+}
+
 type PreviousRank struct {
 	PrevTier string
 	PrevRank string
 	PrevLP   int
+	// PrevGames is the number of ranked games (wins and losses) Riot counted at this rank, 0 when unknown
+	PrevGames int
 }
 
 type LPChange struct {
@@ -600,24 +1325,56 @@ type LPChange struct {
 type SummonerWithGuilds struct {
 	Summoner riotapi.Summoner
 	GuildIDs []string
+	// GuildQueues maps the id of every guild tracking the summoner to the queue ids it tracks for them
+	GuildQueues map[string][]int
+	// LastActiveAt is when the summoner was last seen playing, zero before their first check
+	LastActiveAt time.Time
+	// NameCheckedAt is when the Riot ID of the summoner was last checked, zero before their first check
+	NameCheckedAt time.Time
+}
+
+// TrackedQueues returns every queue id tracked for the summoner by at least one guild.
+func (s SummonerWithGuilds) TrackedQueues() []int {
+	var queueIDs []int
+	seen := make(map[int]bool)
+	for _, guildID := range s.GuildIDs {
+		for _, queueID := range s.GuildQueues[guildID] {
+			if !seen[queueID] {
+				seen[queueID] = true
+				queueIDs = append(queueIDs, queueID)
+			}
+		}
+	}
+
+	return queueIDs
 }
 
-var tierOrder = map[string]int{
-	"IRON":        0,
-	"BRONZE":      1,
-	"SILVER":      2,
-	"GOLD":        3,
-	"PLATINUM":    4,
-	"EMERALD":     5,
-	"DIAMOND":     6,
-	"MASTER":      7,
-	"GRANDMASTER": 8,
-	"CHALLENGER":  9,
+// GuildsTrackingQueue returns the ids of the guilds tracking a queue for the summoner.
+func (s SummonerWithGuilds) GuildsTrackingQueue(queueID int) []string {
+	var guildIDs []string
+	for _, guildID := range s.GuildIDs {
+		for _, id := range s.GuildQueues[guildID] {
+			if id == queueID {
+				guildIDs = append(guildIDs, guildID)
+				break
+			}
+		}
+	}
+
+	return guildIDs
 }
 
-type Season struct {
-	Year  int
-	Split int
+// SeasonResult is the final rank of a summoner in a queue at the end of a split. The peak is empty when
+// it wasn't recorded.
+type SeasonResult struct {
+	SummonerName     string
+	QueueType        string
+	Tier             string
+	Rank             string
+	LeaguePoints     int
+	PeakTier         string
+	PeakRank         string
+	PeakLeaguePoints int
 }
 
 type PlacementGames struct {
@@ -1,7 +1,10 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"time"
 )
 
@@ -19,21 +22,38 @@ var DefaultRetryConfig = RetryConfig{
 	MaxDelay:   30 * time.Second,
 }
 
-// RetryWithBackoff attempts to execute the given function with exponential backoff
-func RetryWithBackoff(operation func() error, config RetryConfig) error {
+// RetryWithBackoff attempts to execute the given function with exponential backoff.
+// It gives up right away when the operation returns a NonRetryableError, and returns it as is.
+// It stops waiting between attempts and returns the context error as soon as ctx is done.
+func RetryWithBackoff(ctx context.Context, operation func() error, config RetryConfig) error {
 	var err error
 	for attempt := 0; attempt < config.MaxRetries; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
 		err = operation()
 		if err == nil {
 			return nil // Success, exit the function
 		}
 
+		var nonRetryable *NonRetryableError
+		if errors.As(err, &nonRetryable) {
+			return err
+		}
+
 		if attempt == config.MaxRetries-1 {
 			break // Last attempt, exit the loop
 		}
 
 		delay := calculateBackoff(attempt, config.BaseDelay, config.MaxDelay)
-		time.Sleep(delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
 	}
 
 	return fmt.Errorf("operation failed after %d attempts: %w", config.MaxRetries, err)
@@ -64,6 +84,15 @@ func NewNonRetryableErrorf(format string, a ...interface{}) *NonRetryableError {
 	return &NonRetryableError{Err: fmt.Errorf(format, a...)}
 }
 
+// BackoffWithJitter returns the exponential backoff delay of an attempt, randomized between half and all of it
+// so that concurrent retries don't hit the same server at the same time.
+func BackoffWithJitter(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
+	delay := calculateBackoff(attempt, baseDelay, maxDelay)
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
 func calculateBackoff(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
 	delay := baseDelay * (1 << attempt) // Exponential backoff
 	return minDuration(delay, maxDelay)
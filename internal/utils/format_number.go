@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"strconv"
+)
+
+// FormatThousands formats an integer with a comma between every group of three digits (e.g. 1234567 -> 1,234,567)
+func FormatThousands(n int) string {
+	if n < 0 {
+		return "-" + FormatThousands(-n)
+	}
+
+	digits := strconv.Itoa(n)
+	var formatted []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			formatted = append(formatted, ',')
+		}
+		formatted = append(formatted, digits[i])
+	}
+
+	return string(formatted)
+}
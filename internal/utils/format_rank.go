@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tierOrder ranks the tiers from the lowest to the highest.
+var tierOrder = map[string]int{
+	"IRON":        0,
+	"BRONZE":      1,
+	"SILVER":      2,
+	"GOLD":        3,
+	"PLATINUM":    4,
+	"EMERALD":     5,
+	"DIAMOND":     6,
+	"MASTER":      7,
+	"GRANDMASTER": 8,
+	"CHALLENGER":  9,
+}
+
+// TierValue returns the position of a tier from the lowest (IRON, 0) to the highest (CHALLENGER, 9),
+// -1 for UNRANKED or an unknown tier.
+func TierValue(tier string) int {
+	value, ok := tierOrder[strings.ToUpper(tier)]
+	if !ok {
+		return -1
+	}
+
+	return value
+}
+
+// CompareRanks compares two ranks by tier then division, LP left out.
+// It returns a negative number when a is lower than b, 0 when they are the same and a positive number otherwise.
+func CompareRanks(tierA, divisionA, tierB, divisionB string) int {
+	if diff := TierValue(tierA) - TierValue(tierB); diff != 0 {
+		return diff
+	}
+
+	return GetRankValue(divisionA) - GetRankValue(divisionB)
+}
+
+// IsApexTier reports whether a tier is Master, Grandmaster or Challenger, which have no division
+// and share a single LP ladder.
+func IsApexTier(tier string) bool {
+	switch strings.ToUpper(tier) {
+	case "MASTER", "GRANDMASTER", "CHALLENGER":
+		return true
+	default:
+		return false
+	}
+}
+
+// FormatRank returns "GOLD II (42lp)", or "MASTER (312lp)" for apex tiers where the division means nothing.
+func FormatRank(tier, division string, leaguePoints int) string {
+	if IsApexTier(tier) || division == "" {
+		return fmt.Sprintf("%s (%dlp)", tier, leaguePoints)
+	}
+
+	return fmt.Sprintf("%s %s (%dlp)", tier, division, leaguePoints)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import "testing"
+
+func TestCompareRanks(t *testing.T) {
+	tests := []struct {
+		tierA, divisionA string
+		tierB, divisionB string
+		want             int
+	}{
+		{tierA: "GOLD", divisionA: "II", tierB: "GOLD", divisionB: "II", want: 0},
+		{tierA: "GOLD", divisionA: "I", tierB: "GOLD", divisionB: "IV", want: 1},
+		{tierA: "GOLD", divisionA: "IV", tierB: "SILVER", divisionB: "I", want: 1},
+		{tierA: "EMERALD", divisionA: "I", tierB: "DIAMOND", divisionB: "IV", want: -1},
+		{tierA: "MASTER", divisionA: "I", tierB: "DIAMOND", divisionB: "I", want: 1},
+		{tierA: "GRANDMASTER", divisionA: "I", tierB: "CHALLENGER", divisionB: "I", want: -1},
+		{tierA: "gold", divisionA: "ii", tierB: "GOLD", divisionB: "II", want: 0},
+		{tierA: "UNRANKED", divisionA: "", tierB: "IRON", divisionB: "IV", want: -1},
+	}
+
+	for _, tt := range tests {
+		got := CompareRanks(tt.tierA, tt.divisionA, tt.tierB, tt.divisionB)
+		if sign(got) != tt.want {
+			t.Errorf("CompareRanks(%s %s, %s %s) = %d, want sign %d", tt.tierA, tt.divisionA, tt.tierB, tt.divisionB, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRank(t *testing.T) {
+	tests := []struct {
+		tier, division string
+		leaguePoints   int
+		want           string
+	}{
+		{tier: "GOLD", division: "II", leaguePoints: 42, want: "GOLD II (42lp)"},
+		{tier: "MASTER", division: "I", leaguePoints: 312, want: "MASTER (312lp)"},
+		{tier: "UNRANKED", division: "", leaguePoints: 0, want: "UNRANKED (0lp)"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatRank(tt.tier, tt.division, tt.leaguePoints); got != tt.want {
+			t.Errorf("FormatRank(%s, %s, %d) = %q, want %q", tt.tier, tt.division, tt.leaguePoints, got, tt.want)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}